@@ -0,0 +1,43 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"math/rand/v2"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/ZachBeta/go_neural_network_learning/internal/utils"
+	"github.com/ZachBeta/go_neural_network_learning/pkg/arena"
+	"github.com/ZachBeta/go_neural_network_learning/pkg/games"
+)
+
+func main() {
+	gameName := flag.String("game", "tictactoe", "game the network plays ("+strings.Join(games.Names(), ", ")+" or mnk-<width>x<height>-<k>)")
+	modelPath := flag.String("model", "checkpoints/latest.json", "path to the saved network to evaluate; policy/value networks play through MCTS")
+	numGames := flag.Int("games", 100, "number of games against each reference opponent")
+	seed := flag.Uint64("seed", uint64(time.Now().UnixNano()), "random seed for the reference opponents")
+	flag.Parse()
+
+	// Keep the board's move logging out of the report
+	utils.SetLogLevel(utils.ERROR)
+
+	g, err := games.New(*gameName)
+	if err != nil {
+		fmt.Printf("Error: %v\n", err)
+		os.Exit(1)
+	}
+
+	rng := rand.New(rand.NewPCG(*seed, *seed))
+	player, err := arena.LoadPlayer(g, *modelPath, filepath.Base(*modelPath), rng)
+	if err != nil {
+		fmt.Printf("Failed to load network: %v\n", err)
+		os.Exit(1)
+	}
+
+	fmt.Printf("Playing %d games of %s against each reference opponent (sides alternate)...\n\n", *numGames, g.Name())
+	report := arena.Evaluate(g, player, arena.ReferencePlayers(g, rng), *numGames)
+	report.Print(os.Stdout)
+}
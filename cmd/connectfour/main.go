@@ -0,0 +1,11 @@
+package main
+
+import (
+	"github.com/ZachBeta/go_neural_network_learning/internal/play"
+	"github.com/ZachBeta/go_neural_network_learning/pkg/connectfour"
+)
+
+// connectfour is the player with Connect Four as its default game
+func main() {
+	play.Main(connectfour.Game{}.Name())
+}
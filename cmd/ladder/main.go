@@ -0,0 +1,162 @@
+package main
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"flag"
+	"fmt"
+	"math/rand/v2"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/ZachBeta/go_neural_network_learning/internal/utils"
+	"github.com/ZachBeta/go_neural_network_learning/pkg/arena"
+	"github.com/ZachBeta/go_neural_network_learning/pkg/game"
+	"github.com/ZachBeta/go_neural_network_learning/pkg/games"
+	"github.com/ZachBeta/go_neural_network_learning/pkg/rating"
+)
+
+func main() {
+	gameName := flag.String("game", "tictactoe", "game the checkpoints play ("+strings.Join(games.Names(), ", ")+" or mnk-<width>x<height>-<k>)")
+	ratingsPath := flag.String("ratings", "checkpoints/ratings.json", "file the ratings are kept in")
+	format := flag.String("format", "roundrobin", "tournament format: roundrobin or swiss")
+	rounds := flag.Int("rounds", 5, "number of rounds in swiss format")
+	numGames := flag.Int("games", 10, "games per pairing (sides alternate)")
+	baselines := flag.Bool("baselines", true, "include the reference players (random, first-legal, and for tic-tac-toe heuristic and perfect)")
+	k := flag.Float64("k", 0, "Elo K-factor (default: keep the ladder's current value)")
+	seed := flag.Uint64("seed", uint64(time.Now().UnixNano()), "random seed for the reference players")
+	flag.Usage = func() {
+		fmt.Fprintf(flag.CommandLine.Output(), "Usage: %s [flags] checkpoint.json...\n", os.Args[0])
+		flag.PrintDefaults()
+	}
+	flag.Parse()
+
+	// Keep the board's move logging out of the leaderboard
+	utils.SetLogLevel(utils.ERROR)
+
+	g, err := games.New(*gameName)
+	if err != nil {
+		fmt.Printf("Error: %v\n", err)
+		os.Exit(1)
+	}
+
+	rng := rand.New(rand.NewPCG(*seed, *seed))
+	players, files, err := loadPlayers(g, flag.Args(), *baselines, rng)
+	if err != nil {
+		fmt.Printf("Error: %v\n", err)
+		os.Exit(1)
+	}
+	if len(players) < 2 {
+		fmt.Println("Need at least two players; pass checkpoint files or enable -baselines.")
+		os.Exit(1)
+	}
+
+	ladder, err := rating.LoadLadder(*ratingsPath)
+	if err != nil {
+		fmt.Printf("Error: %v\n", err)
+		os.Exit(1)
+	}
+	if err := ladder.SetGame(g.Name()); err != nil {
+		fmt.Printf("Error: %s: %v; use -ratings to keep a separate file per game\n", *ratingsPath, err)
+		os.Exit(1)
+	}
+	if *k > 0 {
+		ladder.K = *k
+	}
+	for name, path := range files {
+		ladder.Entry(name).Path = path
+	}
+
+	names := make([]string, len(players))
+	for i, player := range players {
+		names[i] = player.Name()
+	}
+
+	switch *format {
+	case "roundrobin":
+		fmt.Printf("Round robin between %d players, %d games per pairing\n", len(players), *numGames)
+		playPairings(g, ladder, players, rating.RoundRobin(len(players)), *numGames)
+	case "swiss":
+		for round := 0; round < *rounds; round++ {
+			fmt.Printf("Swiss round %d/%d\n", round+1, *rounds)
+			playPairings(g, ladder, players, ladder.SwissRound(names), *numGames)
+		}
+	default:
+		fmt.Printf("Unknown format %q; use roundrobin or swiss\n", *format)
+		os.Exit(1)
+	}
+
+	if err := ladder.Save(*ratingsPath); err != nil {
+		fmt.Printf("Error: %v\n", err)
+		os.Exit(1)
+	}
+
+	fmt.Println()
+	ladder.Print(os.Stdout)
+}
+
+// loadPlayers creates a player for each checkpoint, plus the reference players if requested
+// Checkpoints are named by a hash of their contents, so ratings carry over between runs
+// and follow a model when it is moved, while a file that is overwritten, such as
+// latest.json, starts a new entry. It also returns the absolute path of each named checkpoint.
+func loadPlayers(g game.Game, paths []string, baselines bool, rng *rand.Rand) ([]arena.Player, map[string]string, error) {
+	players := make([]arena.Player, 0, len(paths)+4)
+	files := make(map[string]string, len(paths))
+	for _, path := range paths {
+		name, err := modelHash(path)
+		if err != nil {
+			return nil, nil, fmt.Errorf("%s: %w", path, err)
+		}
+		if other, ok := files[name]; ok {
+			return nil, nil, fmt.Errorf("%s: same model as %s", path, other)
+		}
+		if files[name], err = filepath.Abs(path); err != nil {
+			return nil, nil, fmt.Errorf("%s: %w", path, err)
+		}
+		player, err := arena.LoadPlayer(g, path, name, rng)
+		if err != nil {
+			return nil, nil, err
+		}
+		players = append(players, player)
+	}
+
+	if baselines {
+		players = append(players, arena.ReferencePlayers(g, rng)...)
+	}
+	return players, files, nil
+}
+
+// modelHash returns the start of the SHA-256 hash of the model file at path
+func modelHash(path string) (string, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return "", fmt.Errorf("failed to read model file: %w", err)
+	}
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:6]), nil
+}
+
+// playPairings plays each pairing and records every game on the ladder
+func playPairings(g game.Game, ladder *rating.Ladder, players []arena.Player, pairings []rating.Pairing, games int) {
+	for _, pairing := range pairings {
+		a, b := players[pairing.A], players[pairing.B]
+		for i := 0; i < games; i++ {
+			// Alternate who plays X
+			x, o := a, b
+			if i%2 == 1 {
+				x, o = b, a
+			}
+
+			score := 0.5
+			switch arena.PlayGame(g, x, o) {
+			case game.X:
+				score = 1
+			case game.O:
+				score = 0
+			}
+			ladder.RecordGame(x.Name(), o.Name(), score)
+		}
+	}
+}
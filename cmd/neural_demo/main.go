@@ -1,7 +1,9 @@
 package main
 
 import (
+	"flag"
 	"fmt"
+	"os"
 	"time"
 
 	"github.com/ZachBeta/go_neural_network_learning/pkg/game"
@@ -12,9 +14,24 @@ func main() {
 	// Set random seed for reproducibility
 	neural.SetRandomSeed(time.Now().UnixNano())
 
+	modelPath := flag.String("model", "", "path to a saved network (default: a fresh random network)")
+	flag.Parse()
+
 	// Create a new neural network
 	// 9 inputs (board state), 9 outputs (move probabilities)
 	network := neural.NewNetwork(9, 9)
+	if *modelPath != "" {
+		loaded, err := neural.LoadNetwork(*modelPath)
+		if err != nil {
+			fmt.Printf("Failed to load network: %v\n", err)
+			os.Exit(1)
+		}
+		if err := neural.CheckNetworkFits(loaded, game.TicTacToe{}); err != nil {
+			fmt.Printf("Network does not fit tic-tac-toe: %v\n", err)
+			os.Exit(1)
+		}
+		network = loaded
+	}
 
 	// Print network structure
 	fmt.Println("Neural Network Structure:")
@@ -27,9 +44,9 @@ func main() {
 	fmt.Println("\nInitial Board:")
 	fmt.Println(board)
 
-	// Convert board to neural network input
-	input := neural.BoardToInput(board)
-	fmt.Println("\nNeural Network Input:")
+	// Convert board to neural network input with the encoder the network was trained with
+	input := network.GetEncoder().Encode(board)
+	fmt.Printf("\nNeural Network Input (%s encoder):\n", network.GetEncoder().Name())
 	for i, val := range input {
 		fmt.Printf("Input[%d] = %.2f\n", i, val)
 	}
@@ -39,13 +56,13 @@ func main() {
 	fmt.Println("\nNeural Network Output:")
 	neural.PrintOutput(output)
 
-	// Convert output to move probabilities
-	probabilities := neural.OutputToMoveProbabilities(output)
+	// Convert output to move probabilities over the legal moves
+	probabilities := neural.PredictLegalMoveProbabilities(network, board)
 	fmt.Println("\nMove Probabilities:")
 	neural.PrintMoveProbabilities(probabilities)
 
-	// Select best move
-	bestMove := neural.SelectBestMove(probabilities)
+	// Select best legal move
+	bestMove := neural.SelectBestLegalMove(probabilities, neural.LegalMoveMask(board))
 	row, col := neural.MoveIndexToRowCol(bestMove)
 	fmt.Printf("\nBest Move: (%d,%d)\n", row, col)
 
@@ -54,22 +71,13 @@ func main() {
 	fmt.Println("\nBoard After Move:")
 	fmt.Println(board)
 
-	// Simulate a few more moves
-	for i := 0; i < 3; i++ {
-		// Switch player
-		board.SwitchPlayer()
-
-		// Convert board to neural network input
-		input = neural.BoardToInput(board)
-
-		// Perform forward pass
-		output = network.Forward(input)
-
-		// Convert output to move probabilities
-		probabilities = neural.OutputToMoveProbabilities(output)
+	// Simulate a few more moves; the board switches players itself
+	for i := 0; i < 3 && board.GetStatus() == game.InProgress; i++ {
+		// Get move probabilities over the legal moves
+		probabilities = neural.PredictLegalMoveProbabilities(network, board)
 
-		// Select best move
-		bestMove = neural.SelectBestMove(probabilities)
+		// Select best legal move
+		bestMove = neural.SelectBestLegalMove(probabilities, neural.LegalMoveMask(board))
 		row, col = neural.MoveIndexToRowCol(bestMove)
 
 		// Make the move
@@ -78,7 +86,5 @@ func main() {
 		fmt.Println(board)
 	}
 
-	// Check for winner
-	board.CheckWinner()
 	fmt.Printf("\nGame Status: %v\n", board.GetStatus())
 }
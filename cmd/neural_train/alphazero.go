@@ -0,0 +1,251 @@
+package main
+
+import (
+	"fmt"
+	"math/rand/v2"
+	"path/filepath"
+
+	"github.com/ZachBeta/go_neural_network_learning/pkg/arena"
+	"github.com/ZachBeta/go_neural_network_learning/pkg/game"
+	"github.com/ZachBeta/go_neural_network_learning/pkg/games"
+	"github.com/ZachBeta/go_neural_network_learning/pkg/mcts"
+	"github.com/ZachBeta/go_neural_network_learning/pkg/neural"
+)
+
+// alphaZeroExample is one training position from an MCTS self-play game
+type alphaZeroExample struct {
+	Input  []float64 // the position encoded for the network
+	Policy []float64 // MCTS visit distribution, the policy target
+	Value  float64   // final outcome for the player to move: 1 win, 0 draw, -1 loss
+	Player game.Cell // the player to move
+}
+
+// checkAlphaZeroParams rejects settings that would leave AlphaZero training nothing to average
+func checkAlphaZeroParams(params TrainingParams) error {
+	if params.BatchSize <= 0 {
+		return fmt.Errorf("batch size must be positive, got %d", params.BatchSize)
+	}
+	if params.TrainSteps <= 0 {
+		return fmt.Errorf("-train-steps must be positive, got %d", params.TrainSteps)
+	}
+	return nil
+}
+
+// runAlphaZero trains a policy/value network AlphaZero-style
+// Each iteration the best network plays games with MCTS, the candidate network
+// trains on the stored (position, visit distribution, outcome) examples, and the
+// candidate replaces the best network only if it beats it in a gating match.
+func runAlphaZero(params TrainingParams, seed uint64) error {
+	g, err := games.New(params.Game)
+	if err != nil {
+		return err
+	}
+	candidate, err := newPolicyValueNetwork(g, params)
+	if err != nil {
+		return fmt.Errorf("failed to create network: %w", err)
+	}
+	optimizer, err := neural.NewOptimizer(params.Optimizer, params.LearningRate)
+	if err != nil {
+		return fmt.Errorf("failed to create optimizer: %w", err)
+	}
+
+	rng := rand.New(rand.NewPCG(seed, seed))
+	schedule := neural.StepTemperature(params.Sampling.Temperature, params.FinalTemp, params.TempMoves)
+	best := candidate.Clone()
+
+	// Gating measures strength, so its searches run without root noise;
+	// random openings keep the games from repeating instead
+	gateSearch := params.Search
+	gateSearch.NoiseFraction = 0
+	gate := &openingGame{Game: g, moves: params.GateOpening, rng: rng}
+
+	var examples []alphaZeroExample
+	if err := neural.SavePolicyValueModel(filepath.Join(params.CheckpointDir, "best.json"), best, nil); err != nil {
+		return err
+	}
+
+	fmt.Println("AlphaZero Self-Play Training")
+	fmt.Printf("%d iterations of %d games, %d simulations per move\n\n",
+		params.Iterations, params.IterGames, params.Search.Simulations)
+
+	for iteration := 1; iteration <= params.Iterations; iteration++ {
+		// Generate games with the best network guiding the search
+		selfPlay := mcts.New(mcts.NetworkEvaluator{Network: best}, params.Search, rng)
+		for i := 0; i < params.IterGames; i++ {
+			examples = append(examples, playAlphaZeroGame(g, best, selfPlay, schedule, rng)...)
+		}
+		if len(examples) > params.MaxBufferSize {
+			examples = examples[len(examples)-params.MaxBufferSize:]
+		}
+
+		// Train the candidate on random batches of the stored examples
+		loss := trainPolicyValue(candidate, optimizer, examples, params, rng)
+
+		// Gate: the candidate must score above the threshold against the best network
+		match := arena.PlayMatch(gate,
+			arena.NewMCTSPlayer("candidate", mcts.New(mcts.NetworkEvaluator{Network: candidate}, gateSearch, rng)),
+			arena.NewMCTSPlayer("best", mcts.New(mcts.NetworkEvaluator{Network: best}, gateSearch, rng)),
+			params.GateGames)
+		record := match.Total()
+		promoted := record.Score() > params.GateThreshold
+		status := "kept best"
+		if promoted {
+			best = candidate.Clone()
+			status = "promoted"
+		}
+		fmt.Printf("Iteration %d/%d: %d examples, loss %.3f (policy %.3f, value %.3f), gate %d-%d-%d (%.1f%%) %s\n",
+			iteration, params.Iterations, len(examples), loss.Total, loss.Policy, loss.Value,
+			record.Wins, record.Draws, record.Losses, record.Score()*100, status)
+		gameLogger.Info("Iteration %d: loss %+v, gate %+v, %s", iteration, loss, record, status)
+
+		if err := saveAlphaZeroNetworks(params.CheckpointDir, candidate, best, optimizer, promoted); err != nil {
+			return err
+		}
+	}
+
+	fmt.Println("\nTraining completed!")
+	return nil
+}
+
+// openingGame starts each pair of games from the same random opening
+// PlayMatch alternates sides every game, so both players get each opening as X and as O.
+type openingGame struct {
+	game.Game
+	moves   int
+	rng     *rand.Rand
+	opening game.State
+	started int
+}
+
+// NewState returns a copy of the current opening, drawing a new one every other game
+func (g *openingGame) NewState() game.State {
+	if g.started%2 == 0 {
+		state := g.Game.NewState()
+		for i := 0; i < g.moves && state.GetStatus() == game.InProgress; i++ {
+			moves := state.LegalMoves()
+			move := moves[g.rng.IntN(len(moves))]
+			if _, err := state.Apply(move); err != nil {
+				gameLogger.Error("Rejected illegal opening move %d: %v\n%s", move, err, state.String())
+				break
+			}
+		}
+		g.opening = state
+	}
+	g.started++
+	return g.opening.CloneState()
+}
+
+// newPolicyValueNetwork creates a policy/value network for the game
+// The hidden layers form the shared trunk and use the hidden activation.
+func newPolicyValueNetwork(g game.Game, params TrainingParams) (*neural.PolicyValueNetwork, error) {
+	encoder, err := neural.NewEncoder(params.Encoder)
+	if err != nil {
+		return nil, err
+	}
+	inputSize, err := neural.GameInputSize(encoder, g)
+	if err != nil {
+		return nil, err
+	}
+	activation, err := neural.NewActivation(params.HiddenAct)
+	if err != nil {
+		return nil, err
+	}
+
+	network, err := neural.NewPolicyValueNetwork(append([]int{inputSize}, params.HiddenLayers...), g.ActionSize(), activation)
+	if err != nil {
+		return nil, err
+	}
+	network.Encoder = encoder
+	return network, nil
+}
+
+// playAlphaZeroGame plays one game with MCTS and returns its training examples
+// Every position's policy target is the visit distribution; the move is sampled from
+// it at the temperature the schedule gives for the move number.
+func playAlphaZeroGame(g game.Game, network *neural.PolicyValueNetwork, search *mcts.Search, schedule neural.TemperatureSchedule, rng *rand.Rand) []alphaZeroExample {
+	state := g.NewState()
+	var examples []alphaZeroExample
+
+	for moveNum := 0; state.GetStatus() == game.InProgress; moveNum++ {
+		policy := search.Run(state).Policy(1)
+		examples = append(examples, alphaZeroExample{
+			Input:  neural.EncodeState(network, state),
+			Policy: policy,
+			Player: state.GetCurrentPlayer(),
+		})
+
+		move := neural.SampleMove(policy, neural.SamplingOptions{Temperature: schedule(moveNum)}, rng)
+		if _, err := state.Apply(move); err != nil {
+			gameLogger.Error("Rejected illegal move %d: %v\n%s", move, err, state.String())
+			break
+		}
+	}
+
+	winner, _ := state.Winner()
+	labelOutcome(examples, winner)
+	gameLogger.Info("AlphaZero game: %d moves, winner %v\n%s", len(examples), winner, state.String())
+
+	return examples
+}
+
+// labelOutcome labels every example with the game's outcome for the player who was to move
+func labelOutcome(examples []alphaZeroExample, winner game.Cell) {
+	for i := range examples {
+		switch winner {
+		case game.Empty:
+			examples[i].Value = 0
+		case examples[i].Player:
+			examples[i].Value = 1
+		default:
+			examples[i].Value = -1
+		}
+	}
+}
+
+// trainPolicyValue takes params.TrainSteps optimizer steps on batches drawn from examples
+// and returns the average loss over the last step's batch
+func trainPolicyValue(network *neural.PolicyValueNetwork, optimizer neural.Optimizer, examples []alphaZeroExample, params TrainingParams, rng *rand.Rand) neural.PolicyValueLoss {
+	var average neural.PolicyValueLoss
+	if len(examples) == 0 {
+		return average
+	}
+
+	for step := 0; step < params.TrainSteps; step++ {
+		total := neural.NewGradients(network.GetLayers())
+		average = neural.PolicyValueLoss{}
+		for i := 0; i < params.BatchSize; i++ {
+			example := examples[rng.IntN(len(examples))]
+			grads, loss := network.Gradients(example.Input, example.Policy, example.Value, params.L2)
+			total.Add(grads)
+			average.Policy += loss.Policy
+			average.Value += loss.Value
+			average.L2 += loss.L2
+			average.Total += loss.Total
+		}
+
+		total.Scale(1.0 / float64(params.BatchSize))
+		optimizer.Step(network.GetLayers(), total)
+	}
+
+	scale := 1.0 / float64(params.BatchSize)
+	average.Policy *= scale
+	average.Value *= scale
+	average.L2 *= scale
+	average.Total *= scale
+	return average
+}
+
+// saveAlphaZeroNetworks writes the candidate with its optimizer state to candidate.json
+// and, after a promotion, the new best network to best.json
+func saveAlphaZeroNetworks(dir string, candidate, best *neural.PolicyValueNetwork, optimizer neural.Optimizer, promoted bool) error {
+	if err := neural.SavePolicyValueModel(filepath.Join(dir, "candidate.json"), candidate, optimizer); err != nil {
+		return err
+	}
+	if promoted {
+		if err := neural.SavePolicyValueModel(filepath.Join(dir, "best.json"), best, nil); err != nil {
+			return err
+		}
+		gameLogger.Info("Saved new best network to %s", filepath.Join(dir, "best.json"))
+	}
+	return nil
+}
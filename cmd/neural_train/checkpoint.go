@@ -0,0 +1,212 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"math/rand/v2"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/ZachBeta/go_neural_network_learning/pkg/game"
+	"github.com/ZachBeta/go_neural_network_learning/pkg/games"
+	"github.com/ZachBeta/go_neural_network_learning/pkg/neural"
+)
+
+// checkpointVersion is the version of the training checkpoint format
+// Version 1 buffers hold the final board of each game instead of the board before each move
+const checkpointVersion = 2
+
+// TrainingRun holds everything about a training run that changes from game to game
+// It is what a checkpoint captures and what --resume restores
+type TrainingRun struct {
+	// Game is the name of the game being trained, such as tictactoe or connectfour
+	Game      string
+	Network   *neural.Network
+	Optimizer neural.Optimizer
+	Buffer    *ExperienceBuffer
+	Stats     *TrainingStats
+
+	// Source is the random source behind RNG; it is kept so its state can be saved
+	Source *rand.PCG
+	RNG    *rand.Rand
+
+	// NextGame is the index of the next game to play, which is also the
+	// position in the epsilon schedule
+	NextGame int
+}
+
+// TrainingCheckpoint is the on-disk representation of a TrainingRun
+type TrainingCheckpoint struct {
+	Version  int             `json:"version"`
+	Game     string          `json:"game,omitempty"`
+	NextGame int             `json:"next_game"`
+	Epsilon  float64         `json:"epsilon"`
+	Stats    TrainingStats   `json:"stats"`
+	Buffer   []GameState     `json:"buffer"`
+	RNGState []byte          `json:"rng_state"`
+	Model    json.RawMessage `json:"model"`
+}
+
+// newTrainingRun starts a fresh training run
+func newTrainingRun(params TrainingParams, seed uint64) (*TrainingRun, error) {
+	g, err := games.New(params.Game)
+	if err != nil {
+		return nil, err
+	}
+
+	// Create a new neural network sized for the game
+	network, err := newTrainingNetwork(g, params.Encoder, params.HiddenLayers, params.HiddenAct, params.OutputAct)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create network: %w", err)
+	}
+
+	// Create the optimizer
+	optimizer, err := neural.NewOptimizer(params.Optimizer, params.LearningRate)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create optimizer: %w", err)
+	}
+
+	source := rand.NewPCG(seed, seed)
+	return &TrainingRun{
+		Game:      params.Game,
+		Network:   network,
+		Optimizer: optimizer,
+		Buffer:    NewExperienceBuffer(params.MaxBufferSize, params.Augment),
+		Stats: &TrainingStats{
+			LastSaveTime: time.Now(),
+		},
+		Source: source,
+		RNG:    rand.New(source),
+	}, nil
+}
+
+// resumeTrainingRun restores a training run from a checkpoint file
+// The network, optimizer, game counter, statistics, experience buffer and
+// random number generator all continue exactly where the checkpoint left off
+func resumeTrainingRun(path string, params TrainingParams) (*TrainingRun, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read checkpoint: %w", err)
+	}
+
+	var checkpoint TrainingCheckpoint
+	if err := json.Unmarshal(data, &checkpoint); err != nil {
+		return nil, fmt.Errorf("failed to decode checkpoint: %w", err)
+	}
+	if checkpoint.Version != checkpointVersion && checkpoint.Version != 1 {
+		return nil, fmt.Errorf("unsupported checkpoint version %d (expected %d)", checkpoint.Version, checkpointVersion)
+	}
+
+	// Checkpoints from before Connect Four support are tic-tac-toe runs
+	if checkpoint.Game == "" {
+		checkpoint.Game = game.TicTacToe{}.Name()
+	}
+	if checkpoint.Game != params.Game {
+		return nil, fmt.Errorf("checkpoint is for %s, not %s; use -game %s", checkpoint.Game, params.Game, checkpoint.Game)
+	}
+
+	network, optimizer, err := neural.ReadModel(bytes.NewReader(checkpoint.Model))
+	if err != nil {
+		return nil, err
+	}
+	if optimizer == nil {
+		return nil, fmt.Errorf("checkpoint has no optimizer state")
+	}
+	if encoder := network.GetEncoder().Name(); encoder != params.Encoder {
+		return nil, fmt.Errorf("checkpoint uses the %s encoder, not %s; use -encoder %s", encoder, params.Encoder, encoder)
+	}
+
+	source := &rand.PCG{}
+	if err := source.UnmarshalBinary(checkpoint.RNGState); err != nil {
+		return nil, fmt.Errorf("failed to restore random number generator: %w", err)
+	}
+
+	// The saved states were already augmented when they were first added
+	buffer := NewExperienceBuffer(params.MaxBufferSize, params.Augment)
+	for _, state := range checkpoint.Buffer {
+		// A version 1 board is not the position the move was played in, so it can't be trained on
+		if checkpoint.Version == 1 && state.Board != nil {
+			continue
+		}
+		buffer.push(state)
+	}
+
+	stats := checkpoint.Stats
+	return &TrainingRun{
+		Game:      checkpoint.Game,
+		Network:   network,
+		Optimizer: optimizer,
+		Buffer:    buffer,
+		Stats:     &stats,
+		Source:    source,
+		RNG:       rand.New(source),
+		NextGame:  checkpoint.NextGame,
+	}, nil
+}
+
+// saveCheckpoint saves the network and the full training state after gameNum has been played
+// Besides the network_*.json model files written by saveNetwork, it writes a
+// checkpoint_*.json file that --resume can continue from
+func saveCheckpoint(dir string, run *TrainingRun, gameNum int, epsilon float64) {
+	saveNetwork(dir, run.Network, run.Optimizer, gameNum)
+
+	run.NextGame = gameNum + 1
+	run.Stats.LastSaveTime = time.Now()
+
+	if err := writeCheckpoint(dir, run, epsilon); err != nil {
+		gameLogger.Error("Failed to save training checkpoint: %v", err)
+		fmt.Printf("\nFailed to save training checkpoint: %v\n", err)
+	}
+}
+
+// writeCheckpoint encodes the training run and writes it to the checkpoint directory
+func writeCheckpoint(dir string, run *TrainingRun, epsilon float64) error {
+	var model bytes.Buffer
+	if err := neural.WriteModel(&model, run.Network, run.Optimizer); err != nil {
+		return err
+	}
+
+	rngState, err := run.Source.MarshalBinary()
+	if err != nil {
+		return fmt.Errorf("failed to save random number generator: %w", err)
+	}
+
+	checkpoint := TrainingCheckpoint{
+		Version:  checkpointVersion,
+		Game:     run.Game,
+		NextGame: run.NextGame,
+		Epsilon:  epsilon,
+		Stats:    *run.Stats,
+		Buffer:   run.Buffer.states,
+		RNGState: rngState,
+		Model:    model.Bytes(),
+	}
+
+	data, err := json.Marshal(checkpoint)
+	if err != nil {
+		return fmt.Errorf("failed to encode checkpoint: %w", err)
+	}
+
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return fmt.Errorf("failed to create checkpoint directory: %w", err)
+	}
+	paths := []string{
+		filepath.Join(dir, fmt.Sprintf("checkpoint_%06d.json", run.NextGame)),
+		filepath.Join(dir, "checkpoint_latest.json"),
+	}
+	for _, path := range paths {
+		// Write to a temporary file first so an interrupted save keeps the old checkpoint
+		tmp := path + ".tmp"
+		if err := os.WriteFile(tmp, data, 0644); err != nil {
+			return fmt.Errorf("failed to write checkpoint: %w", err)
+		}
+		if err := os.Rename(tmp, path); err != nil {
+			return fmt.Errorf("failed to move checkpoint into place: %w", err)
+		}
+	}
+
+	gameLogger.Info("Saved training checkpoint to %s", paths[0])
+	return nil
+}
@@ -1,17 +1,24 @@
 package main
 
 import (
+	"flag"
 	"fmt"
 	"math"
-	"math/rand"
+	"math/rand/v2"
 	"os"
 	"os/signal"
+	"path/filepath"
+	"strings"
 	"syscall"
 	"time"
 
+	"github.com/ZachBeta/go_neural_network_learning/internal/utils"
 	"github.com/ZachBeta/go_neural_network_learning/pkg/display"
 	"github.com/ZachBeta/go_neural_network_learning/pkg/game"
+	"github.com/ZachBeta/go_neural_network_learning/pkg/games"
+	"github.com/ZachBeta/go_neural_network_learning/pkg/mcts"
 	"github.com/ZachBeta/go_neural_network_learning/pkg/neural"
+	"github.com/ZachBeta/go_neural_network_learning/pkg/strategy"
 )
 
 // TrainingStats tracks training statistics
@@ -19,7 +26,7 @@ type TrainingStats struct {
 	XWins         int
 	OWins         int
 	Draws         int
-	MoveCounts    [9]int
+	MoveCounts    []int // indexed by move; grows to the game's action size
 	ForkCreates   int
 	ForkBlocks    int
 	WinningMoves  int
@@ -29,27 +36,64 @@ type TrainingStats struct {
 
 // TrainingParams holds the parameters for self-play training
 type TrainingParams struct {
+	Game          string
+	Encoder       string
 	NumGames      int
+	HiddenLayers  []int
+	HiddenAct     string
+	OutputAct     string
 	BatchSize     int
 	LearningRate  float64
+	Optimizer     string
 	EpsilonStart  float64
 	EpsilonEnd    float64
 	EpsilonDecay  float64
+	Exploration   string
+	Sampling      neural.SamplingOptions
+	TempMoves     int
+	FinalTemp     float64
+	MCTS          bool        // choose moves from a tree search guided by the network
+	Search        mcts.Config // search parameters used when MCTS is set
+	AlphaZero     bool        // train a policy/value network on MCTS self-play with gated promotion
+	Iterations    int         // AlphaZero iterations of self-play, training and gating
+	IterGames     int         // self-play games per AlphaZero iteration
+	TrainSteps    int         // optimizer steps per AlphaZero iteration
+	L2            float64     // weight decay for the policy/value network
+	GateGames     int         // arena games between the candidate and the best network
+	GateThreshold float64     // score the candidate needs to exceed to replace the best network
+	GateOpening   int         // random moves played before each pair of gating games
 	DisplayDelay  time.Duration
 	SaveInterval  int
+	CheckpointDir string
 	MaxBufferSize int
+	Augment       bool
 	LogInterval   int
 }
 
-// GameState represents a single state in a game
+// GameState represents a single state in a game: the position the player faced and the move it made
 type GameState struct {
-	Board         *game.Board
+	Board         *game.Board // snapshot of the tic-tac-toe board before Move; never shared between states
+	Input         []float64   `json:",omitempty"` // encoded position for games other than tic-tac-toe, which have no Board
 	Move          int
 	Result        float64 // 1.0 for win, -1.0 for loss, 0.0 for draw
 	Player        string  // "X" or "O"
 	Probabilities []float64
 }
 
+// Transform returns the state with the board, move and probabilities mapped by the symmetry
+func (s GameState) Transform(symmetry game.Symmetry) GameState {
+	probabilities := make([]float64, len(s.Probabilities))
+	for i, prob := range s.Probabilities {
+		probabilities[symmetry.TransformIndex(i)] = prob
+	}
+
+	transformed := s
+	transformed.Board = s.Board.Transform(symmetry)
+	transformed.Move = symmetry.TransformIndex(s.Move)
+	transformed.Probabilities = probabilities
+	return transformed
+}
+
 // GameRecord represents a complete game
 type GameRecord struct {
 	States []GameState
@@ -60,27 +104,83 @@ type GameRecord struct {
 type ExperienceBuffer struct {
 	states  []GameState
 	maxSize int
+	// augment stores every symmetric variant of each added state
+	augment bool
+	// stored counts the buffered states by position, move and result when augmenting
+	stored map[variantKey]int
+}
+
+// variantKey identifies an augmented state; symmetric copies of one state share
+// their set of keys, so a state is only stored once however it is added
+type variantKey struct {
+	hash   uint64
+	move   int
+	result float64
+}
+
+// keyOf returns the key of a state with a board
+func keyOf(state GameState) variantKey {
+	return variantKey{state.Board.Hash(), state.Move, state.Result}
 }
 
 // NewExperienceBuffer creates a new experience buffer
-func NewExperienceBuffer(maxSize int) *ExperienceBuffer {
+// With augment set, each added state is stored in all of its symmetric variants
+func NewExperienceBuffer(maxSize int, augment bool) *ExperienceBuffer {
 	return &ExperienceBuffer{
 		states:  make([]GameState, 0, maxSize),
 		maxSize: maxSize,
+		augment: augment,
+		stored:  make(map[variantKey]int),
 	}
 }
 
 // Add adds a new game state to the buffer
+// With augmentation the state is added once for each distinct symmetric variant
+// that the buffer does not already hold
 func (b *ExperienceBuffer) Add(state GameState) {
+	if !b.augment {
+		b.push(state)
+		return
+	}
+
+	// Symmetric boards (such as the empty board) map some variants onto each other,
+	// and a symmetric copy of a stored state maps onto the stored variants;
+	// store each distinct position, move and result only once
+	for _, symmetry := range game.Symmetries {
+		variant := state.Transform(symmetry)
+		if b.stored[keyOf(variant)] > 0 {
+			continue
+		}
+		b.push(variant)
+	}
+}
+
+// push stores a single state, dropping the oldest one when the buffer is full
+func (b *ExperienceBuffer) push(state GameState) {
 	if len(b.states) >= b.maxSize {
 		// Remove oldest state
+		b.forget(b.states[0])
 		b.states = b.states[1:]
 	}
 	b.states = append(b.states, state)
+	if b.augment && state.Board != nil {
+		b.stored[keyOf(state)]++
+	}
+}
+
+// forget drops a state that left the buffer from the stored counts
+func (b *ExperienceBuffer) forget(state GameState) {
+	if !b.augment || state.Board == nil {
+		return
+	}
+	key := keyOf(state)
+	if b.stored[key]--; b.stored[key] == 0 {
+		delete(b.stored, key)
+	}
 }
 
 // Sample returns a random batch of states
-func (b *ExperienceBuffer) Sample(batchSize int) []GameState {
+func (b *ExperienceBuffer) Sample(batchSize int, rng *rand.Rand) []GameState {
 	if len(b.states) < batchSize {
 		return b.states
 	}
@@ -91,7 +191,7 @@ func (b *ExperienceBuffer) Sample(batchSize int) []GameState {
 
 	// Shuffle the buffer
 	for i := len(buffer) - 1; i > 0; i-- {
-		j := rand.Intn(i + 1)
+		j := rng.IntN(i + 1)
 		buffer[i], buffer[j] = buffer[j], buffer[i]
 	}
 
@@ -107,35 +207,137 @@ func (b *ExperienceBuffer) Size() int {
 // DefaultTrainingParams returns the default training parameters
 func DefaultTrainingParams() TrainingParams {
 	return TrainingParams{
+		Game:          game.TicTacToe{}.Name(),
+		Encoder:       neural.AbsoluteEncoder{}.Name(),
 		NumGames:      1000,
+		HiddenLayers:  []int{64, 32},
+		HiddenAct:     "sigmoid",
+		OutputAct:     "sigmoid",
 		BatchSize:     32,
 		LearningRate:  0.01,
+		Optimizer:     "sgd",
 		EpsilonStart:  0.9,
 		EpsilonEnd:    0.1,
 		EpsilonDecay:  0.995,
+		Exploration:   "epsilon",
+		Sampling:      neural.SamplingOptions{Temperature: 1.0},
+		TempMoves:     3,
+		FinalTemp:     0.1,
+		Search:        mcts.DefaultConfig(),
+		Iterations:    20,
+		IterGames:     25,
+		TrainSteps:    100,
+		L2:            1e-4,
+		GateGames:     40,
+		GateThreshold: 0.55,
+		GateOpening:   2,
 		DisplayDelay:  500 * time.Millisecond,
 		SaveInterval:  100,
+		CheckpointDir: "checkpoints",
 		MaxBufferSize: 10000,
 		LogInterval:   10, // Log every 10 games
 	}
 }
 
 func main() {
-	// Set random seed for reproducibility
-	rand.Seed(time.Now().UnixNano())
-
 	// Get training parameters
 	params := DefaultTrainingParams()
+	flag.StringVar(&params.Game, "game", params.Game,
+		"game to train on ("+strings.Join(games.Names(), ", ")+" or mnk-<width>x<height>-<k>)")
+	flag.StringVar(&params.Encoder, "encoder", params.Encoder,
+		"board input encoding ("+strings.Join(neural.EncoderNames(), ", ")+"); saved with the network")
+	flag.StringVar(&params.Optimizer, "optimizer", params.Optimizer,
+		"optimizer to use ("+strings.Join(neural.OptimizerNames(), ", ")+")")
+	flag.StringVar(&params.HiddenAct, "activation", params.HiddenAct,
+		"hidden layer activation ("+strings.Join(neural.ActivationNames(), ", ")+")")
+	flag.StringVar(&params.OutputAct, "output-activation", params.OutputAct, "output layer activation")
+	flag.Float64Var(&params.LearningRate, "lr", params.LearningRate, "learning rate")
+	flag.StringVar(&params.CheckpointDir, "checkpoint-dir", params.CheckpointDir, "directory for saved networks")
+	flag.IntVar(&params.NumGames, "games", params.NumGames, "number of self-play games")
+	flag.BoolVar(&params.Augment, "augment", params.Augment, "add all 8 rotations and reflections of each position to the experience buffer")
+	flag.StringVar(&params.Exploration, "exploration", params.Exploration,
+		"exploration strategy: epsilon (epsilon-greedy) or sample (sample from move probabilities)")
+	flag.Float64Var(&params.Sampling.Temperature, "temperature", params.Sampling.Temperature, "sampling temperature for the opening moves")
+	flag.IntVar(&params.TempMoves, "temperature-moves", params.TempMoves, "number of opening moves sampled at -temperature")
+	flag.Float64Var(&params.FinalTemp, "final-temperature", params.FinalTemp, "sampling temperature after the opening moves")
+	flag.IntVar(&params.Sampling.TopK, "top-k", params.Sampling.TopK, "sample only from the k most likely moves (0 = all)")
+	flag.Float64Var(&params.Sampling.TopP, "top-p", params.Sampling.TopP, "sample only from the most likely moves covering this probability mass (0 = all)")
+	flag.BoolVar(&params.MCTS, "mcts", params.MCTS,
+		"choose moves by Monte Carlo Tree Search guided by the network instead of its raw output")
+	flag.IntVar(&params.Search.Simulations, "simulations", params.Search.Simulations, "MCTS simulations per move")
+	flag.Float64Var(&params.Search.CPuct, "cpuct", params.Search.CPuct, "MCTS exploration constant")
+	flag.Float64Var(&params.Search.DirichletAlpha, "dirichlet-alpha", params.Search.DirichletAlpha, "concentration of the MCTS root noise")
+	flag.Float64Var(&params.Search.NoiseFraction, "noise", params.Search.NoiseFraction, "fraction of the MCTS root priors replaced by noise (0 = none)")
+	flag.BoolVar(&params.AlphaZero, "alphazero", params.AlphaZero,
+		"train a policy/value network AlphaZero-style: MCTS self-play, training and gating against the best network")
+	flag.IntVar(&params.Iterations, "iterations", params.Iterations, "AlphaZero iterations")
+	flag.IntVar(&params.IterGames, "iteration-games", params.IterGames, "AlphaZero self-play games per iteration")
+	flag.IntVar(&params.TrainSteps, "train-steps", params.TrainSteps, "AlphaZero training batches per iteration")
+	flag.Float64Var(&params.L2, "l2", params.L2, "AlphaZero weight decay")
+	flag.IntVar(&params.GateGames, "gate-games", params.GateGames, "AlphaZero gating games between the candidate and the best network")
+	flag.Float64Var(&params.GateThreshold, "gate-threshold", params.GateThreshold, "score the candidate must exceed to be promoted")
+	flag.IntVar(&params.GateOpening, "gate-opening", params.GateOpening, "random opening moves before each pair of gating games")
+	resumePath := flag.String("resume", "", "resume training from a checkpoint_*.json file")
+	seed := flag.Uint64("seed", uint64(time.Now().UnixNano()), "random seed for a new training run")
+	flag.Parse()
+
+	// Keep the board's logging out of the progress output; details go to logs/
+	utils.SetLogLevel(utils.ERROR)
+
+	// Set random seed for weight initialization
+	neural.SetRandomSeed(int64(*seed))
+
+	if params.AlphaZero {
+		if *resumePath != "" {
+			fmt.Println("-resume cannot be used with -alphazero")
+			os.Exit(1)
+		}
+		if err := checkAlphaZeroParams(params); err != nil {
+			fmt.Printf("Error: %v\n", err)
+			os.Exit(1)
+		}
+		if err := runAlphaZero(params, *seed); err != nil {
+			fmt.Printf("AlphaZero training failed: %v\n", err)
+			os.Exit(1)
+		}
+		return
+	}
 
-	// Create a new neural network
-	network := neural.NewNetwork(9, 9)
+	// Start a new run or pick up an interrupted one
+	var run *TrainingRun
+	var err error
+	if *resumePath != "" {
+		run, err = resumeTrainingRun(*resumePath, params)
+	} else {
+		run, err = newTrainingRun(params, *seed)
+	}
+	if err != nil {
+		fmt.Printf("Failed to start training: %v\n", err)
+		os.Exit(1)
+	}
+	g, err := games.New(run.Game)
+	if err != nil {
+		fmt.Printf("Failed to start training: %v\n", err)
+		os.Exit(1)
+	}
+	if _, ok := g.NewState().(*game.Board); params.Augment && !ok {
+		fmt.Printf("-augment uses tic-tac-toe symmetries and cannot be used with %s\n", g.Name())
+		os.Exit(1)
+	}
+	if params.Exploration != "epsilon" && params.Exploration != "sample" {
+		fmt.Printf("Unknown exploration strategy %q; use epsilon or sample\n", params.Exploration)
+		os.Exit(1)
+	}
+	schedule := neural.StepTemperature(params.Sampling.Temperature, params.FinalTemp, params.TempMoves)
 
-	// Create experience buffer
-	buffer := NewExperienceBuffer(params.MaxBufferSize)
+	stats := run.Stats
 
-	// Initialize training stats
-	stats := &TrainingStats{
-		LastSaveTime: time.Now(),
+	// The search reads the network's weights as they are updated, so one search serves every game.
+	// Its priors come from the network and leaves are valued by random playouts,
+	// since the network has no value output.
+	var search *mcts.Search
+	if params.MCTS {
+		search = mcts.New(mcts.RolloutEvaluator{Policy: run.Network, RNG: run.RNG}, params.Search, run.RNG)
 	}
 
 	// Create a channel for handling interrupts
@@ -146,32 +348,18 @@ func main() {
 	fmt.Println("Neural Network Self-Play Training")
 	fmt.Println("Detailed logs will be written to logs/training_*.log")
 	fmt.Println("Press Ctrl+C to stop training")
+	if run.NextGame > 0 {
+		fmt.Printf("Resuming from game %d\n", run.NextGame+1)
+	}
 	fmt.Println()
 
 	// Start a goroutine to handle user input
 	go handleUserInput(interrupt)
 
 	// Training loop
-	for gameNum := 0; gameNum < params.NumGames; gameNum++ {
-		// Calculate exploration rate
-		epsilon := math.Max(params.EpsilonEnd, params.EpsilonStart*math.Pow(params.EpsilonDecay, float64(gameNum)))
-
-		// Play a game and collect experience
-		record := playGameWithVisualization(network, epsilon, params.DisplayDelay)
-
-		// Update statistics
-		updateStats(stats, record)
-
-		// Add game states to buffer
-		for _, state := range record.States {
-			buffer.Add(state)
-		}
-
-		// Sample batch and update network
-		if buffer.Size() >= params.BatchSize {
-			batch := buffer.Sample(params.BatchSize)
-			updateNetworkWeights(network, batch, params.LearningRate)
-		}
+	for gameNum := run.NextGame; gameNum < params.NumGames; gameNum++ {
+		// Play a game and learn from it
+		record, epsilon := trainGame(run, g, search, schedule, params, gameNum)
 
 		// Display progress
 		displayTrainingProgress(gameNum, params.NumGames, record, epsilon)
@@ -180,7 +368,7 @@ func main() {
 		if gameNum > 0 && gameNum%params.LogInterval == 0 {
 			// Move to next line after progress bar
 			fmt.Println()
-			logDetailedStats(gameNum, stats, epsilon)
+			logDetailedStats(params.Game, gameNum, stats, epsilon)
 		}
 
 		// Display statistics every 100 games
@@ -190,16 +378,16 @@ func main() {
 
 		// Save network periodically
 		if gameNum > 0 && gameNum%params.SaveInterval == 0 {
-			saveNetwork(network, gameNum)
-			stats.LastSaveTime = time.Now()
+			saveCheckpoint(params.CheckpointDir, run, gameNum, epsilon)
 		}
 
 		// Check for interrupt
 		select {
 		case <-interrupt:
 			fmt.Println("\nTraining interrupted. Saving network...")
-			saveNetwork(network, gameNum)
-			logDetailedStats(gameNum, stats, epsilon)
+			saveCheckpoint(params.CheckpointDir, run, gameNum, epsilon)
+			logDetailedStats(params.Game, gameNum, stats, epsilon)
+			fmt.Printf("Resume with: --resume %s\n", filepath.Join(params.CheckpointDir, "checkpoint_latest.json"))
 			return
 		default:
 			// Continue training
@@ -208,10 +396,78 @@ func main() {
 
 	// Training completed
 	fmt.Println("\nTraining completed!")
-	logDetailedStats(params.NumGames-1, stats, params.EpsilonEnd)
+	logDetailedStats(params.Game, params.NumGames-1, stats, params.EpsilonEnd)
 
 	// Save the final network
-	saveNetwork(network, params.NumGames-1)
+	saveCheckpoint(params.CheckpointDir, run, params.NumGames-1, params.EpsilonEnd)
+}
+
+// trainGame plays game gameNum of the run, adds its positions to the buffer and
+// takes one training step; it returns the game and the exploration rate it used
+func trainGame(run *TrainingRun, g game.Game, search *mcts.Search, schedule neural.TemperatureSchedule, params TrainingParams, gameNum int) (GameRecord, float64) {
+	// Calculate exploration rate
+	epsilon := math.Max(params.EpsilonEnd, params.EpsilonStart*math.Pow(params.EpsilonDecay, float64(gameNum)))
+
+	// Choose how moves are explored in this game
+	selectMove := epsilonGreedySelector(epsilon, run.RNG)
+	if params.Exploration == "sample" {
+		selectMove = samplingSelector(schedule, params.Sampling, run.RNG)
+	}
+
+	// Play a game and collect experience
+	record := playGameWithVisualization(run.Network, g, search, selectMove, epsilon, params.DisplayDelay)
+
+	// Update statistics
+	updateStats(run.Stats, record)
+
+	// Add game states to buffer
+	for _, state := range record.States {
+		run.Buffer.Add(state)
+	}
+
+	// Sample batch and update network
+	if run.Buffer.Size() >= params.BatchSize {
+		batch := run.Buffer.Sample(params.BatchSize, run.RNG)
+		updateNetworkWeights(run.Network, run.Optimizer, batch)
+	}
+
+	return record, epsilon
+}
+
+// newTrainingNetwork creates a network for the game with the given encoder and hidden layer sizes
+// The encoder and activation functions are looked up by name
+func newTrainingNetwork(g game.Game, encoderName string, hiddenLayers []int, hiddenActivation, outputActivation string) (*neural.Network, error) {
+	encoder, err := neural.NewEncoder(encoderName)
+	if err != nil {
+		return nil, err
+	}
+	inputSize, err := neural.GameInputSize(encoder, g)
+	if err != nil {
+		return nil, err
+	}
+
+	layerSizes := append([]int{inputSize}, hiddenLayers...)
+	layerSizes = append(layerSizes, g.ActionSize())
+
+	activations := make([]neural.ActivationFunction, len(layerSizes)-1)
+	for i := range activations {
+		name := hiddenActivation
+		if i == len(activations)-1 {
+			name = outputActivation
+		}
+		activation, err := neural.NewActivation(name)
+		if err != nil {
+			return nil, err
+		}
+		activations[i] = activation
+	}
+
+	network, err := neural.NewMultiLayerNetwork(layerSizes, activations)
+	if err != nil {
+		return nil, err
+	}
+	network.Encoder = encoder
+	return network, nil
 }
 
 // handleUserInput handles user input during training
@@ -232,26 +488,32 @@ func updateStats(stats *TrainingStats, record GameRecord) {
 
 	// Update move counts and strategy detection
 	for _, state := range record.States {
+		for len(stats.MoveCounts) <= state.Move {
+			stats.MoveCounts = append(stats.MoveCounts, 0)
+		}
 		stats.MoveCounts[state.Move]++
 
-		// Check for strategic moves
-		if isForkCreation(state.Board, state.Move) {
+		// Check for strategic moves; the detectors only know tic-tac-toe
+		if state.Board == nil {
+			continue
+		}
+		if strategy.IsForkCreation(state.Board, state.Move) {
 			stats.ForkCreates++
 		}
-		if isForkBlocking(state.Board, state.Move) {
+		if strategy.IsForkBlocking(state.Board, state.Move) {
 			stats.ForkBlocks++
 		}
-		if isWinningMove(state.Board, state.Move) {
+		if strategy.IsWinningMove(state.Board, state.Move) {
 			stats.WinningMoves++
 		}
-		if isBlockingMove(state.Board, state.Move) {
+		if strategy.IsBlockingMove(state.Board, state.Move) {
 			stats.BlockingMoves++
 		}
 	}
 }
 
 // logDetailedStats logs detailed training statistics
-func logDetailedStats(gameNum int, stats *TrainingStats, epsilon float64) {
+func logDetailedStats(gameName string, gameNum int, stats *TrainingStats, epsilon float64) {
 	// Calculate percentages
 	xWinRate := float64(stats.XWins) / float64(gameNum+1) * 100
 	oWinRate := float64(stats.OWins) / float64(gameNum+1) * 100
@@ -267,8 +529,12 @@ func logDetailedStats(gameNum int, stats *TrainingStats, epsilon float64) {
 	// Log move distribution
 	gameLogger.Info("\nMove Distribution:")
 	for i, count := range stats.MoveCounts {
-		row, col := neural.MoveIndexToRowCol(i)
 		percentage := float64(count) / float64(gameNum+1) * 100
+		if gameName != (game.TicTacToe{}).Name() {
+			gameLogger.Info("  Move %d: %.1f%% (%d moves)", i, percentage, count)
+			continue
+		}
+		row, col := neural.MoveIndexToRowCol(i)
 		gameLogger.Info("  Position (%d,%d): %.1f%% (%d moves)", row, col, percentage, count)
 	}
 
@@ -290,7 +556,21 @@ func logDetailedStats(gameNum int, stats *TrainingStats, epsilon float64) {
 	gameLogger.Info("==========================================\n")
 }
 
-// saveNetwork saves the network to a file
-func saveNetwork(network *neural.Network, gameNum int) {
-	// Implementation will be added later
+// saveNetwork saves the network and optimizer state to a checkpoint file
+// Each checkpoint is named after the game it was taken at, and latest.json
+// always holds the most recent one
+func saveNetwork(dir string, network *neural.Network, optimizer neural.Optimizer, gameNum int) {
+	paths := []string{
+		filepath.Join(dir, fmt.Sprintf("network_%06d.json", gameNum+1)),
+		filepath.Join(dir, "latest.json"),
+	}
+
+	for _, path := range paths {
+		if err := neural.SaveModel(path, network, optimizer); err != nil {
+			gameLogger.Error("Failed to save network to %s: %v", path, err)
+			fmt.Printf("\nFailed to save network: %v\n", err)
+			return
+		}
+	}
+	gameLogger.Info("Saved network checkpoint to %s", paths[0])
 }
@@ -0,0 +1,288 @@
+package main
+
+import (
+	"bytes"
+	"math"
+	"math/rand/v2"
+	"path/filepath"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/ZachBeta/go_neural_network_learning/internal/utils"
+	"github.com/ZachBeta/go_neural_network_learning/pkg/game"
+	"github.com/ZachBeta/go_neural_network_learning/pkg/games"
+	"github.com/ZachBeta/go_neural_network_learning/pkg/mcts"
+	"github.com/ZachBeta/go_neural_network_learning/pkg/neural"
+)
+
+func init() {
+	utils.SetLogLevel(utils.ERROR)
+}
+
+// testParams returns small training parameters that write into dir
+func testParams(dir string) TrainingParams {
+	params := DefaultTrainingParams()
+	params.HiddenLayers = []int{16}
+	params.Optimizer = "adam"
+	params.BatchSize = 8
+	params.MaxBufferSize = 40
+	params.Augment = true
+	params.DisplayDelay = 0
+	params.CheckpointDir = dir
+	return params
+}
+
+// modelBytes encodes the run's network and optimizer state
+func modelBytes(t *testing.T, run *TrainingRun) []byte {
+	t.Helper()
+	var buf bytes.Buffer
+	if err := neural.WriteModel(&buf, run.Network, run.Optimizer); err != nil {
+		t.Fatalf("WriteModel: %v", err)
+	}
+	return buf.Bytes()
+}
+
+func TestResumeCheckpoint(t *testing.T) {
+	params := testParams(t.TempDir())
+	g, err := games.New(params.Game)
+	if err != nil {
+		t.Fatal(err)
+	}
+	schedule := neural.StepTemperature(params.Sampling.Temperature, params.FinalTemp, params.TempMoves)
+
+	run, err := newTrainingRun(params, 7)
+	if err != nil {
+		t.Fatalf("newTrainingRun: %v", err)
+	}
+	const checkpointGame, totalGames = 3, 7
+	for gameNum := 0; gameNum <= checkpointGame; gameNum++ {
+		trainGame(run, g, nil, schedule, params, gameNum)
+	}
+	saveCheckpoint(params.CheckpointDir, run, checkpointGame, 0)
+
+	resumed, err := resumeTrainingRun(filepath.Join(params.CheckpointDir, "checkpoint_latest.json"), params)
+	if err != nil {
+		t.Fatalf("resumeTrainingRun: %v", err)
+	}
+	if resumed.NextGame != checkpointGame+1 || resumed.NextGame != run.NextGame {
+		t.Fatalf("NextGame = %d, want %d", resumed.NextGame, checkpointGame+1)
+	}
+
+	// Both runs play the remaining games; the resumed run must not be able to tell it was interrupted
+	for gameNum := run.NextGame; gameNum < totalGames; gameNum++ {
+		trainGame(run, g, nil, schedule, params, gameNum)
+		trainGame(resumed, g, nil, schedule, params, gameNum)
+	}
+
+	if !reflect.DeepEqual(resumed.Buffer.states, run.Buffer.states) {
+		t.Errorf("resumed buffer holds %d states, differing from the uninterrupted run's %d",
+			resumed.Buffer.Size(), run.Buffer.Size())
+	}
+
+	// The save time is wall-clock bookkeeping, not training state
+	want, got := *run.Stats, *resumed.Stats
+	want.LastSaveTime, got.LastSaveTime = time.Time{}, time.Time{}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("resumed stats = %+v, want %+v", got, want)
+	}
+
+	if !bytes.Equal(modelBytes(t, resumed), modelBytes(t, run)) {
+		t.Error("resumed network or optimizer state differs from the uninterrupted run")
+	}
+
+	for i := 0; i < 5; i++ {
+		if got, want := resumed.RNG.Uint64(), run.RNG.Uint64(); got != want {
+			t.Fatalf("random draw %d = %d, want %d", i, got, want)
+		}
+	}
+}
+
+func TestExperienceBufferAugment(t *testing.T) {
+	// X in the center and O answering in a corner: the diagonal reflection through
+	// that corner maps the position and move onto themselves, leaving 4 distinct images
+	board := game.NewBoard()
+	if _, err := board.MakeMove(1, 1); err != nil {
+		t.Fatalf("MakeMove(1, 1): %v", err)
+	}
+	probabilities := make([]float64, 9)
+	for i := range probabilities {
+		probabilities[i] = float64(i+1) / 45
+	}
+	state := GameState{Board: board, Move: 0, Result: 1, Player: "O", Probabilities: probabilities}
+
+	buffer := NewExperienceBuffer(100, true)
+	buffer.Add(state)
+
+	want := map[variantKey]game.Symmetry{}
+	for _, symmetry := range game.Symmetries {
+		key := variantKey{board.Transform(symmetry).Hash(), symmetry.TransformIndex(state.Move), state.Result}
+		if _, ok := want[key]; !ok {
+			want[key] = symmetry
+		}
+	}
+	if len(want) != 4 {
+		t.Fatalf("position has %d distinct images, want 4", len(want))
+	}
+	if buffer.Size() != len(want) {
+		t.Fatalf("buffer holds %d states, want the %d distinct images", buffer.Size(), len(want))
+	}
+
+	seen := map[variantKey]bool{}
+	for _, stored := range buffer.states {
+		key := keyOf(stored)
+		symmetry, ok := want[key]
+		if !ok || seen[key] {
+			t.Fatalf("buffer holds an unexpected or repeated state:\n%s move %d", stored.Board, stored.Move)
+		}
+		seen[key] = true
+
+		// The move and probabilities travel with the board; Add keeps the first
+		// symmetry in Symmetries order that produces each image
+		if !reflect.DeepEqual(stored, state.Transform(symmetry)) {
+			t.Errorf("%v image = %+v, want %+v", symmetry, stored, state.Transform(symmetry))
+		}
+		if stored.Probabilities[stored.Move] != state.Probabilities[state.Move] {
+			t.Errorf("%v image: move %d does not carry the played move's probability", symmetry, stored.Move)
+		}
+	}
+
+	// A symmetric copy of the same experience is already stored
+	buffer.Add(state.Transform(game.Rotate90))
+	if buffer.Size() != len(want) {
+		t.Errorf("re-adding a rotated copy grew the buffer to %d states, want %d", buffer.Size(), len(want))
+	}
+}
+
+func TestLabelOutcome(t *testing.T) {
+	players := []game.Cell{game.X, game.O, game.X, game.O}
+	tests := []struct {
+		winner game.Cell
+		want   []float64
+	}{
+		{game.X, []float64{1, -1, 1, -1}},
+		{game.O, []float64{-1, 1, -1, 1}},
+		{game.Empty, []float64{0, 0, 0, 0}},
+	}
+	for _, tt := range tests {
+		examples := make([]alphaZeroExample, len(players))
+		for i, player := range players {
+			examples[i].Player = player
+		}
+		labelOutcome(examples, tt.winner)
+		for i, example := range examples {
+			if example.Value != tt.want[i] {
+				t.Errorf("winner %v: example %d (%v to move) value = %v, want %v",
+					tt.winner, i, example.Player, example.Value, tt.want[i])
+			}
+		}
+	}
+}
+
+// alphaZeroSetup returns a small tic-tac-toe policy/value network and a search guided by it
+func alphaZeroSetup(t *testing.T) (game.Game, TrainingParams, *neural.PolicyValueNetwork, *mcts.Search, *rand.Rand) {
+	t.Helper()
+	neural.SetRandomSeed(1)
+	params := testParams(t.TempDir())
+	params.HiddenLayers = []int{16}
+	params.Search.Simulations = 20
+	g, err := games.New(params.Game)
+	if err != nil {
+		t.Fatal(err)
+	}
+	network, err := newPolicyValueNetwork(g, params)
+	if err != nil {
+		t.Fatalf("newPolicyValueNetwork: %v", err)
+	}
+	rng := rand.New(rand.NewPCG(1, 2))
+	return g, params, network, mcts.New(mcts.NetworkEvaluator{Network: network}, params.Search, rng), rng
+}
+
+func TestPlayAlphaZeroGame(t *testing.T) {
+	g, params, network, search, rng := alphaZeroSetup(t)
+	schedule := neural.StepTemperature(params.Sampling.Temperature, params.FinalTemp, params.TempMoves)
+
+	examples := playAlphaZeroGame(g, network, search, schedule, rng)
+	if len(examples) < 5 {
+		t.Fatalf("game produced %d examples, want at least 5", len(examples))
+	}
+	for i, example := range examples {
+		// The absolute encoding marks occupied cells, which are not legal moves
+		sum := 0.0
+		for move, prob := range example.Policy {
+			if example.Input[move] != 0 && prob != 0 {
+				t.Errorf("example %d: occupied cell %d has policy %v", i, move, prob)
+			}
+			sum += prob
+		}
+		if math.Abs(sum-1) > 1e-9 {
+			t.Errorf("example %d: policy sums to %v, want 1", i, sum)
+		}
+
+		// Every position carries the same outcome, seen from the player to move
+		want := examples[0].Value
+		if example.Player != examples[0].Player {
+			want = -want
+		}
+		if example.Value != want {
+			t.Errorf("example %d (%v to move) value = %v, want %v", i, example.Player, example.Value, want)
+		}
+	}
+}
+
+func TestOpeningGame(t *testing.T) {
+	g, _, _, _, rng := alphaZeroSetup(t)
+	opening := &openingGame{Game: g, moves: 2, rng: rng}
+
+	states := make([]game.State, 6)
+	for i := range states {
+		states[i] = opening.NewState()
+	}
+	for k := 0; k < len(states); k += 2 {
+		first, second := states[k], states[k+1]
+		if first.String() != second.String() {
+			t.Errorf("games %d and %d start from different openings:\n%s\n%s", k, k+1, first, second)
+		}
+		if moves := len(first.(*game.Board).History()); moves != 2 {
+			t.Errorf("opening for games %d and %d has %d moves, want 2", k, k+1, moves)
+		}
+	}
+
+	// Each game gets its own copy of the opening
+	if _, err := states[0].Apply(states[0].LegalMoves()[0]); err != nil {
+		t.Fatalf("Apply: %v", err)
+	}
+	if states[0].String() == states[1].String() {
+		t.Error("playing on one game's opening changed the other's")
+	}
+}
+
+func TestTrainPolicyValue(t *testing.T) {
+	g, params, network, search, rng := alphaZeroSetup(t)
+	schedule := neural.StepTemperature(params.Sampling.Temperature, params.FinalTemp, params.TempMoves)
+	examples := playAlphaZeroGame(g, network, search, schedule, rng)
+
+	batchLoss := func() float64 {
+		total := 0.0
+		for _, example := range examples {
+			total += network.Loss(example.Input, example.Policy, example.Value, params.L2).Total
+		}
+		return total / float64(len(examples))
+	}
+
+	optimizer, err := neural.NewOptimizer("adam", 0.01)
+	if err != nil {
+		t.Fatal(err)
+	}
+	params.TrainSteps = 50
+	before := batchLoss()
+	trainPolicyValue(network, optimizer, examples, params, rng)
+	if after := batchLoss(); after >= before {
+		t.Errorf("loss on the training examples went from %v to %v, want it lower", before, after)
+	}
+
+	params.BatchSize = 0
+	if err := checkAlphaZeroParams(params); err == nil {
+		t.Error("checkAlphaZeroParams accepted a batch size of 0")
+	}
+}
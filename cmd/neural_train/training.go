@@ -2,13 +2,15 @@ package main
 
 import (
 	"log"
-	"math/rand"
+	"math/rand/v2"
 	"time"
 
 	"github.com/ZachBeta/go_neural_network_learning/pkg/display"
 	"github.com/ZachBeta/go_neural_network_learning/pkg/game"
 	"github.com/ZachBeta/go_neural_network_learning/pkg/logger"
+	"github.com/ZachBeta/go_neural_network_learning/pkg/mcts"
 	"github.com/ZachBeta/go_neural_network_learning/pkg/neural"
+	"github.com/ZachBeta/go_neural_network_learning/pkg/strategy"
 )
 
 var (
@@ -26,10 +28,39 @@ func init() {
 	gameLogger.Info("Training session started")
 }
 
-// playGameWithVisualization plays a complete game and returns the game record
-func playGameWithVisualization(network *neural.Network, epsilon float64, displayDelay time.Duration) GameRecord {
-	// Create a new game board
-	board := game.NewBoard()
+// moveSelector chooses a move given the network's legal move probabilities, the mask
+// of legal moves and the move number within the game
+type moveSelector func(probabilities []float64, mask []bool, moveNum int) int
+
+// epsilonGreedySelector plays a random legal move with probability epsilon and
+// the network's best move otherwise
+func epsilonGreedySelector(epsilon float64, rng *rand.Rand) moveSelector {
+	return func(probabilities []float64, mask []bool, moveNum int) int {
+		if rng.Float64() < epsilon {
+			// Exploration: choose a random valid move
+			return selectRandomValidMove(mask, rng)
+		}
+		// Exploitation: use network's prediction
+		return neural.SelectBestLegalMove(probabilities, mask)
+	}
+}
+
+// samplingSelector samples moves from the network's probabilities, using the
+// temperature schedule for the move number and the top-k/top-p truncation in opts
+func samplingSelector(schedule neural.TemperatureSchedule, opts neural.SamplingOptions, rng *rand.Rand) moveSelector {
+	return func(probabilities []float64, mask []bool, moveNum int) int {
+		moveOpts := opts
+		moveOpts.Temperature = schedule(moveNum)
+		return neural.SampleMove(probabilities, moveOpts, rng)
+	}
+}
+
+// playGameWithVisualization plays a complete game of g and returns the game record
+// epsilon is only used for logging; selectMove decides how moves are explored.
+// With a search, moves are chosen from its visit counts instead of the network's output.
+func playGameWithVisualization(network *neural.Network, g game.Game, search *mcts.Search, selectMove moveSelector, epsilon float64, displayDelay time.Duration) GameRecord {
+	// Create the starting position
+	state := g.NewState()
 
 	// Create a game record
 	record := GameRecord{
@@ -39,71 +70,67 @@ func playGameWithVisualization(network *neural.Network, epsilon float64, display
 
 	// Play until the game is over
 	moveNum := 0
-	for board.GetStatus() == game.InProgress {
+	for state.GetStatus() == game.InProgress {
 		// Get the current player
-		currentPlayer := board.GetCurrentPlayer()
+		currentPlayer := state.GetCurrentPlayer()
 		playerStr := "X"
 		if currentPlayer == game.O {
 			playerStr = "O"
 		}
 
-		// Convert board to neural network input
-		input := neural.BoardToInput(board)
-
-		// Get move probabilities
-		output := network.Forward(input)
-		probabilities := neural.OutputToMoveProbabilities(output)
-
-		// Select a move
-		var move int
-		if rand.Float64() < epsilon {
-			// Exploration: choose a random valid move
-			move = selectRandomValidMove(board)
+		// Encode the position and get move probabilities over the legal moves
+		input := neural.EncodeState(network, state)
+		mask := neural.StateMoveMask(state)
+		var probabilities []float64
+		if search != nil {
+			probabilities = search.Run(state).Policy(1)
 		} else {
-			// Exploitation: use network's prediction
-			move = neural.SelectBestMove(probabilities)
+			probabilities = neural.PredictMaskedProbabilities(network, input, mask)
 		}
 
-		// Convert move index to row and column
-		row, col := neural.MoveIndexToRowCol(move)
-
-		// Make the move
-		board.MakeMove(row, col)
+		// Select a move
+		move := selectMove(probabilities, mask, moveNum)
 
-		// Create a game state
-		state := GameState{
-			Board:         board,
+		// Record the position the player faced; tic-tac-toe keeps a snapshot of its
+		// board for the strategy detectors and augmentation, other games keep the
+		// encoded position. The snapshot must be taken before the move is applied.
+		gameState := GameState{
 			Move:          move,
 			Player:        playerStr,
 			Probabilities: probabilities,
 		}
-
-		// Add the state to the record
-		record.States = append(record.States, state)
+		if board, ok := state.(*game.Board); ok {
+			gameState.Board = board.Clone()
+		} else {
+			gameState.Input = input
+		}
 
 		// Log the game state to file
-		logGameState(board, move, moveNum, playerStr, epsilon, probabilities)
+		logGameState(state, move, moveNum, playerStr, epsilon, probabilities)
+
+		// Make the move; masking guarantees it is legal, but never record a rejected move
+		if _, err := state.Apply(move); err != nil {
+			gameLogger.Error("Rejected illegal move %d: %v\n%s", move, err, state.String())
+			break
+		}
+
+		// Add the state to the record
+		record.States = append(record.States, gameState)
 
 		// Wait for the specified delay (very short for training)
 		time.Sleep(time.Millisecond)
 
-		// Check for winner
-		board.CheckWinner()
-
 		// Increment move number
 		moveNum++
 	}
 
 	// Set the winner
-	if board.GetStatus() == game.Won {
-		// Switch back to the previous player since they won
-		board.SwitchPlayer()
-		if board.GetCurrentPlayer() == game.X {
-			record.Winner = "X"
-		} else {
-			record.Winner = "O"
-		}
-	} else {
+	switch winner, _ := state.Winner(); winner {
+	case game.X:
+		record.Winner = "X"
+	case game.O:
+		record.Winner = "O"
+	default:
 		record.Winner = "Draw"
 	}
 
@@ -125,10 +152,27 @@ func playGameWithVisualization(network *neural.Network, epsilon float64, display
 	return record
 }
 
-// logGameState logs detailed game state information to file
-func logGameState(board *game.Board, move, moveNum int, player string, epsilon float64, probabilities []float64) {
-	row, col := neural.MoveIndexToRowCol(move)
+// logGameState logs the position a player faced and the move it chose to file
+// The strategic analysis and board evaluation only apply to tic-tac-toe
+func logGameState(state game.State, move, moveNum int, player string, epsilon float64, probabilities []float64) {
 	gameLogger.Info("\nMove %d - Player %s (ε=%.3f)", moveNum+1, player, epsilon)
+	board, isBoard := state.(*game.Board)
+	if !isBoard {
+		gameLogger.Info("Selected move: %d", move)
+		gameLogger.Info("Board state:\n%s", state.String())
+		gameLogger.Info("Move probabilities:")
+		for i, prob := range probabilities {
+			if i == move {
+				gameLogger.Info("  Move %d: %.2f%% [SELECTED]", i, prob*100)
+			} else {
+				gameLogger.Info("  Move %d: %.2f%%", i, prob*100)
+			}
+		}
+		gameLogger.Info("==========================================\n")
+		return
+	}
+
+	row, col := neural.MoveIndexToRowCol(move)
 	gameLogger.Info("Selected move: (%d,%d)", row, col)
 	gameLogger.Info("Board state:\n%s", board.String())
 
@@ -145,16 +189,16 @@ func logGameState(board *game.Board, move, moveNum int, player string, epsilon f
 
 	// Log strategic analysis
 	gameLogger.Info("\nStrategic Analysis:")
-	if isForkCreation(board, move) {
+	if strategy.IsForkCreation(board, move) {
 		gameLogger.Info("  ✓ Fork Creation detected")
 	}
-	if isForkBlocking(board, move) {
+	if strategy.IsForkBlocking(board, move) {
 		gameLogger.Info("  ✓ Fork Blocking detected")
 	}
-	if isWinningMove(board, move) {
+	if strategy.IsWinningMove(board, move) {
 		gameLogger.Info("  ✓ Winning Move detected")
 	}
-	if isBlockingMove(board, move) {
+	if strategy.IsBlockingMove(board, move) {
 		gameLogger.Info("  ✓ Blocking Move detected")
 	}
 
@@ -192,16 +236,20 @@ func logGameResult(record GameRecord) {
 	blockingMoves := 0
 
 	for _, state := range record.States {
-		if isForkCreation(state.Board, state.Move) {
+		// The detectors only know tic-tac-toe
+		if state.Board == nil {
+			continue
+		}
+		if strategy.IsForkCreation(state.Board, state.Move) {
 			forkCreations++
 		}
-		if isForkBlocking(state.Board, state.Move) {
+		if strategy.IsForkBlocking(state.Board, state.Move) {
 			forkBlocks++
 		}
-		if isWinningMove(state.Board, state.Move) {
+		if strategy.IsWinningMove(state.Board, state.Move) {
 			winningMoves++
 		}
-		if isBlockingMove(state.Board, state.Move) {
+		if strategy.IsBlockingMove(state.Board, state.Move) {
 			blockingMoves++
 		}
 	}
@@ -214,20 +262,19 @@ func logGameResult(record GameRecord) {
 	gameLogger.Info("==========================================\n")
 }
 
-// selectRandomValidMove selects a random valid move
-func selectRandomValidMove(board *game.Board) int {
+// selectRandomValidMove selects a random move allowed by the mask
+func selectRandomValidMove(mask []bool, rng *rand.Rand) int {
 	// Get all valid moves
-	validMoves := make([]int, 0)
-	for i := 0; i < 9; i++ {
-		row, col := neural.MoveIndexToRowCol(i)
-		if board.Get(row, col) == game.Empty {
-			validMoves = append(validMoves, i)
+	validMoves := make([]int, 0, len(mask))
+	for move, legal := range mask {
+		if legal {
+			validMoves = append(validMoves, move)
 		}
 	}
 
 	// Select a random move
 	if len(validMoves) > 0 {
-		return validMoves[rand.Intn(len(validMoves))]
+		return validMoves[rng.IntN(len(validMoves))]
 	}
 
 	// This should never happen if the game is not over
@@ -235,43 +282,36 @@ func selectRandomValidMove(board *game.Board) int {
 }
 
 // updateNetworkWeights updates the network weights based on a batch of game states
-func updateNetworkWeights(network *neural.Network, batch []GameState, learningRate float64) {
-	// For each state in the batch
+// Gradients are computed with backpropagation, averaged over the batch and applied by the optimizer
+func updateNetworkWeights(network *neural.Network, optimizer neural.Optimizer, batch []GameState) {
+	if len(batch) == 0 {
+		return
+	}
+
+	total := neural.NewGradients(network.GetLayers())
 	for _, state := range batch {
-		// Convert board to neural network input
-		input := neural.BoardToInput(state.Board)
+		// Encode the board the way the network expects, unless the game encoded it already
+		input := state.Input
+		if input == nil {
+			input = neural.EncodeState(network, state.Board)
+		}
+		output := network.Forward(input)
 
-		// Get the target output
-		target := make([]float64, 9)
-		target[state.Move] = state.Result
+		// Only the move that was actually played has a known target.
+		// Map the game result from [-1, 1] into the output range [0, 1] so
+		// moves that led to a win are pushed up and moves that led to a loss down.
+		target := (state.Result + 1.0) / 2.0
 
-		// Update the network weights
-		// This is a simplified version of backpropagation
-		// In a real implementation, we would use a proper backpropagation algorithm
-		output := network.Forward(input)
-		for i := range output {
-			// Calculate the error
-			error := target[i] - output[i]
-
-			// Update the weights
-			// This is a very simplified version
-			// In a real implementation, we would update the weights properly
-			// using the error and the derivative of the activation function
-			for j := range input {
-				// Update the weight
-				// This is a very simplified version
-				// In a real implementation, we would update the weights properly
-				// using the error and the derivative of the activation function
-				network.GetOutputLayer().GetNeuron(i).Weights[j] += learningRate * error * input[j]
-			}
+		// Gradient of the squared error for the played move
+		lossGrad := make([]float64, len(output))
+		lossGrad[state.Move] = output[state.Move] - target
 
-			// Update the bias
-			// This is a very simplified version
-			// In a real implementation, we would update the bias properly
-			// using the error and the derivative of the activation function
-			network.GetOutputLayer().GetNeuron(i).Bias += learningRate * error
-		}
+		total.Add(network.Backward(lossGrad))
 	}
+
+	// Average over the batch and let the optimizer take a step
+	total.Scale(1.0 / float64(len(batch)))
+	optimizer.Step(network.GetLayers(), total)
 }
 
 // displayTrainingProgress displays the training progress
@@ -282,14 +322,7 @@ func displayTrainingProgress(gameNum, totalGames int, record GameRecord, epsilon
 
 // Helper functions for board evaluation
 func countEmptyCells(board *game.Board) int {
-	count := 0
-	for i := 0; i < 9; i++ {
-		row, col := neural.MoveIndexToRowCol(i)
-		if board.Get(row, col) == game.Empty {
-			count++
-		}
-	}
-	return count
+	return len(board.EmptyCells())
 }
 
 func countPotentialWinningLines(board *game.Board) int {
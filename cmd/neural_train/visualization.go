@@ -8,6 +8,7 @@ import (
 
 	"github.com/ZachBeta/go_neural_network_learning/pkg/game"
 	"github.com/ZachBeta/go_neural_network_learning/pkg/neural"
+	"github.com/ZachBeta/go_neural_network_learning/pkg/strategy"
 )
 
 // ClearScreen clears the terminal screen
@@ -72,28 +73,35 @@ func DisplayTrainingProgress(gameNum, totalGames int, xWins, oWins, draws int, e
 // DisplayStrategyInfo displays information about the strategy used
 func DisplayStrategyInfo(board *game.Board, move int) {
 	// Check for fork creation
-	if isForkCreation(board, move) {
+	if strategy.IsForkCreation(board, move) {
 		gameLogger.Info("\n[STRATEGY] Fork Creation Detected!")
 	}
 
 	// Check for fork blocking
-	if isForkBlocking(board, move) {
+	if strategy.IsForkBlocking(board, move) {
 		gameLogger.Info("\n[STRATEGY] Fork Blocking Detected!")
 	}
 
 	// Check for winning move
-	if isWinningMove(board, move) {
+	if strategy.IsWinningMove(board, move) {
 		gameLogger.Info("\n[STRATEGY] Winning Move Detected!")
 	}
 
 	// Check for blocking opponent's winning move
-	if isBlockingMove(board, move) {
+	if strategy.IsBlockingMove(board, move) {
 		gameLogger.Info("\n[STRATEGY] Blocking Move Detected!")
 	}
 }
 
-// VisualizeGame visualizes a complete game with the given delay
+// VisualizeGame visualizes a complete tic-tac-toe game with the given delay
+// Each step shows the position the player faced, so the probabilities and
+// strategy info describe the choice it actually had
 func VisualizeGame(record GameRecord, displayDelay time.Duration) {
+	if len(record.States) == 0 || record.States[0].Board == nil {
+		DisplayGameResult(record)
+		return
+	}
+
 	for i, state := range record.States {
 		ClearScreen()
 
@@ -113,101 +121,11 @@ func VisualizeGame(record GameRecord, displayDelay time.Duration) {
 		time.Sleep(displayDelay)
 	}
 
-	// Display final result
+	// Display final result: the last position with its move played
 	ClearScreen()
-	DisplayBoard(record.States[len(record.States)-1].Board)
+	last := record.States[len(record.States)-1]
+	final := last.Board.Clone()
+	final.Apply(last.Move)
+	DisplayBoard(final)
 	DisplayGameResult(record)
 }
-
-// isForkCreation checks if a move creates a fork
-func isForkCreation(board *game.Board, move int) bool {
-	// Make a temporary board to test the move
-	tempBoard := board.Clone()
-	row, col := neural.MoveIndexToRowCol(move)
-	tempBoard.MakeMove(row, col)
-
-	// Count winning lines after the move
-	winningLines := 0
-	for i := 0; i < 9; i++ {
-		testRow, testCol := neural.MoveIndexToRowCol(i)
-		if tempBoard.Get(testRow, testCol) == game.Empty {
-			// Try this move
-			testBoard := tempBoard.Clone()
-			testBoard.MakeMove(testRow, testCol)
-			testBoard.CheckWinner()
-			if testBoard.GetStatus() == game.Won {
-				winningLines++
-			}
-		}
-	}
-
-	// A fork creates at least 2 winning lines
-	return winningLines >= 2
-}
-
-// isForkBlocking checks if a move blocks a fork
-func isForkBlocking(board *game.Board, move int) bool {
-	// Make a temporary board to test the move
-	tempBoard := board.Clone()
-	row, col := neural.MoveIndexToRowCol(move)
-	tempBoard.MakeMove(row, col)
-
-	// Count opponent's winning lines after our move
-	winningLines := 0
-	for i := 0; i < 9; i++ {
-		testRow, testCol := neural.MoveIndexToRowCol(i)
-		if tempBoard.Get(testRow, testCol) == game.Empty {
-			// Try opponent's move
-			testBoard := tempBoard.Clone()
-			testBoard.SwitchPlayer()
-			testBoard.MakeMove(testRow, testCol)
-			testBoard.CheckWinner()
-			if testBoard.GetStatus() == game.Won {
-				winningLines++
-			}
-		}
-	}
-
-	// A fork block prevents opponent from having 2 winning lines
-	return winningLines < 2
-}
-
-// isWinningMove checks if a move is a winning move
-func isWinningMove(board *game.Board, move int) bool {
-	// Make a temporary board to test the move
-	tempBoard := board.Clone()
-	row, col := neural.MoveIndexToRowCol(move)
-	tempBoard.MakeMove(row, col)
-	tempBoard.CheckWinner()
-
-	// Check if this move wins
-	return tempBoard.GetStatus() == game.Won
-}
-
-// isBlockingMove checks if a move blocks the opponent's winning move
-func isBlockingMove(board *game.Board, move int) bool {
-	// Make a temporary board to test the move
-	tempBoard := board.Clone()
-	row, col := neural.MoveIndexToRowCol(move)
-	tempBoard.MakeMove(row, col)
-
-	// Switch to opponent's turn
-	tempBoard.SwitchPlayer()
-
-	// Check if opponent can win after our move
-	for i := 0; i < 9; i++ {
-		testRow, testCol := neural.MoveIndexToRowCol(i)
-		if tempBoard.Get(testRow, testCol) == game.Empty {
-			// Try opponent's move
-			testBoard := tempBoard.Clone()
-			testBoard.MakeMove(testRow, testCol)
-			testBoard.CheckWinner()
-			if testBoard.GetStatus() == game.Won {
-				return false // Opponent can still win
-			}
-		}
-	}
-
-	// If we get here, opponent cannot win after our move
-	return true
-}
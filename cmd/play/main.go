@@ -0,0 +1,11 @@
+package main
+
+import (
+	"github.com/ZachBeta/go_neural_network_learning/internal/play"
+	"github.com/ZachBeta/go_neural_network_learning/pkg/game"
+)
+
+// play plays any game the games registry knows, chosen with -game
+func main() {
+	play.Main(game.TicTacToe{}.Name())
+}
@@ -0,0 +1,20 @@
+package gametest
+
+import (
+	"testing"
+
+	"github.com/ZachBeta/go_neural_network_learning/pkg/game"
+)
+
+// Board plays the given cell indices (0-8) on a new tic-tac-toe board
+// The test stops at once if a move is rejected.
+func Board(t testing.TB, moves ...int) *game.Board {
+	t.Helper()
+	board := game.NewBoard()
+	for _, move := range moves {
+		if _, err := board.Apply(move); err != nil {
+			t.Fatalf("move %d rejected: %v\n%s", move, err, board)
+		}
+	}
+	return board
+}
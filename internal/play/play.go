@@ -0,0 +1,294 @@
+package play
+
+import (
+	"bufio"
+	"flag"
+	"fmt"
+	"math/rand/v2"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/ZachBeta/go_neural_network_learning/internal/utils"
+	"github.com/ZachBeta/go_neural_network_learning/pkg/arena"
+	"github.com/ZachBeta/go_neural_network_learning/pkg/game"
+	"github.com/ZachBeta/go_neural_network_learning/pkg/games"
+	"github.com/ZachBeta/go_neural_network_learning/pkg/neural"
+	"github.com/ZachBeta/go_neural_network_learning/pkg/ultimate"
+)
+
+// Game modes
+const (
+	modeHumanVsHuman     = "hvh"
+	modeHumanVsNetwork   = "hvn"
+	modeNetworkVsNetwork = "nvn"
+)
+
+// hinter is a player that can show how it rates each move
+type hinter interface {
+	Probabilities(state game.State) []float64
+}
+
+// playerToString converts a Cell value to a string representation
+func playerToString(player game.Cell) string {
+	switch player {
+	case game.X:
+		return "X"
+	case game.O:
+		return "O"
+	default:
+		return "Unknown"
+	}
+}
+
+// Main runs the terminal player for the commands in cmd
+// defaultGame is the game played when -game is not given.
+func Main(defaultGame string) {
+	gameName := flag.String("game", defaultGame,
+		"game to play ("+strings.Join(games.Names(), ", ")+" or mnk-<width>x<height>-<k>)")
+	mode := flag.String("mode", modeHumanVsHuman, "game mode: hvh (human vs human), hvn (human vs network), nvn (network vs network)")
+	modelPath := flag.String("model", "", "path to a network trained on the game with neural_train (required for hvn and nvn); policy/value networks play through MCTS")
+	opponentPath := flag.String("model2", "", "path to the network playing O in nvn mode (default: same as -model)")
+	humanSide := flag.String("human", "X", "side the human plays in hvn mode (X or O)")
+	hints := flag.Bool("hints", false, "show the network's move probabilities before each human move")
+	flag.Parse()
+
+	g, err := games.New(*gameName)
+	if err != nil {
+		fmt.Printf("Error: %v\n", err)
+		os.Exit(1)
+	}
+
+	// players maps each side to the network playing it; a missing side is a human
+	rng := rand.New(rand.NewPCG(uint64(time.Now().UnixNano()), 0))
+	players, hintPlayer, err := setupPlayers(g, *mode, *modelPath, *opponentPath, *humanSide, rng)
+	if err != nil {
+		fmt.Printf("Error: %v\n", err)
+		flag.Usage()
+		os.Exit(1)
+	}
+	if *hints && hintPlayer == nil {
+		fmt.Println("Hints need a network; use -model to load one.")
+		*hints = false
+	}
+
+	fmt.Printf("Welcome to %s!\n", g.Name())
+	fmt.Println("Enter 'q' to quit at any time.")
+
+	// Keep the board's logging out of the game
+	utils.SetLogLevel(utils.ERROR)
+
+	state := g.NewState()
+	moves := movesFor(state)
+	reader := bufio.NewReader(os.Stdin)
+
+	// Game loop
+	for {
+		// Display the board
+		fmt.Println("\nCurrent board:")
+		fmt.Print(state.String())
+
+		// Check if the game is over
+		if state.GetStatus() != game.InProgress {
+			fmt.Println("Game over!")
+			if winner, _ := state.Winner(); winner != game.Empty {
+				fmt.Printf("Player %s wins!\n", playerToString(winner))
+			} else {
+				fmt.Println("It's a draw!")
+			}
+			break
+		}
+
+		// Let the network move if it controls the current side
+		if player, ok := players[state.GetCurrentPlayer()]; ok {
+			move := player.SelectMove(state)
+			fmt.Printf("Network (%s) plays %s\n", playerToString(state.GetCurrentPlayer()), moves.format(move))
+			state.Apply(move)
+			continue
+		}
+
+		if *hints {
+			printHints(hintPlayer, state, moves)
+		}
+
+		// Get player input
+		fmt.Printf("Player %s's turn. %s: ", playerToString(state.GetCurrentPlayer()), moves.prompt)
+
+		input, err := reader.ReadString('\n')
+		utils.HandleError(err, false)
+		if err != nil {
+			// Stop on end of input instead of spinning on the same error
+			break
+		}
+
+		// Check for quit command
+		input = strings.TrimSpace(input)
+		if input == "q" {
+			fmt.Println("Quitting game.")
+			break
+		}
+
+		move, err := moves.parse(strings.Fields(input))
+		if err != nil {
+			fmt.Printf("Invalid input: %v. %s.\n", err, moves.prompt)
+			continue
+		}
+
+		// Make the move; the state reports why a move is rejected
+		if _, err := state.Apply(move); err != nil {
+			fmt.Printf("Invalid move: %v. Try again.\n", err)
+			continue
+		}
+	}
+}
+
+// moveInput describes how a human types a game's moves and how moves are shown
+type moveInput struct {
+	prompt string
+	parse  func(fields []string) (int, error)
+	format func(move int) string
+}
+
+// movesFor returns the move input for a state
+// Boards take a row and column; other games, such as Connect Four, take the move number.
+func movesFor(state game.State) moveInput {
+	switch s := state.(type) {
+	case game.Grid:
+		width := s.Width()
+		return rowColInput(s.Height(), width,
+			func(row, col int) int { return neural.RowColToIndex(row, col, width) },
+			func(move int) (int, int) { return neural.IndexToRowCol(move, width) })
+	case *ultimate.Board:
+		return rowColInput(9, 9, ultimate.FromRowCol, ultimate.RowCol)
+	}
+
+	last := state.ActionSize() - 1
+	return moveInput{
+		prompt: fmt.Sprintf("Enter a move (0-%d)", last),
+		parse: func(fields []string) (int, error) {
+			if len(fields) != 1 {
+				return 0, fmt.Errorf("expected one number")
+			}
+			move, err := strconv.Atoi(fields[0])
+			if err != nil || move < 0 || move > last {
+				return 0, fmt.Errorf("%q is not a number between 0 and %d", fields[0], last)
+			}
+			return move, nil
+		},
+		format: func(move int) string {
+			return strconv.Itoa(move)
+		},
+	}
+}
+
+// rowColInput returns a move input that reads "row col" on a rows x cols grid
+func rowColInput(rows, cols int, toMove func(row, col int) int, fromMove func(move int) (int, int)) moveInput {
+	return moveInput{
+		prompt: fmt.Sprintf("Enter row (0-%d) and column (0-%d) separated by space", rows-1, cols-1),
+		parse: func(fields []string) (int, error) {
+			if len(fields) != 2 {
+				return 0, fmt.Errorf("expected a row and a column")
+			}
+			row, err := strconv.Atoi(fields[0])
+			if err != nil || row < 0 || row >= rows {
+				return 0, fmt.Errorf("row %q is not between 0 and %d", fields[0], rows-1)
+			}
+			col, err := strconv.Atoi(fields[1])
+			if err != nil || col < 0 || col >= cols {
+				return 0, fmt.Errorf("column %q is not between 0 and %d", fields[1], cols-1)
+			}
+			return toMove(row, col), nil
+		},
+		format: func(move int) string {
+			row, col := fromMove(move)
+			return fmt.Sprintf("%d %d", row, col)
+		},
+	}
+}
+
+// setupPlayers loads the networks needed for the chosen mode
+// It returns the network player for each side a human does not play and the player used for hints
+func setupPlayers(g game.Game, mode, modelPath, opponentPath, humanSide string, rng *rand.Rand) (map[game.Cell]arena.Player, hinter, error) {
+	players := map[game.Cell]arena.Player{}
+
+	var network arena.Player
+	if modelPath != "" {
+		loaded, err := arena.LoadPlayer(g, modelPath, filepath.Base(modelPath), rng)
+		if err != nil {
+			return nil, nil, err
+		}
+		network = loaded
+	}
+
+	switch mode {
+	case modeHumanVsHuman:
+		return players, hints(network), nil
+
+	case modeHumanVsNetwork:
+		if network == nil {
+			return nil, nil, fmt.Errorf("mode %s needs a network; use -model", mode)
+		}
+		switch strings.ToUpper(humanSide) {
+		case "X":
+			players[game.O] = network
+		case "O":
+			players[game.X] = network
+		default:
+			return nil, nil, fmt.Errorf("invalid side %q; choose X or O", humanSide)
+		}
+		return players, hints(network), nil
+
+	case modeNetworkVsNetwork:
+		if network == nil {
+			return nil, nil, fmt.Errorf("mode %s needs a network; use -model", mode)
+		}
+		opponent := network
+		if opponentPath != "" {
+			loaded, err := arena.LoadPlayer(g, opponentPath, filepath.Base(opponentPath), rng)
+			if err != nil {
+				return nil, nil, err
+			}
+			opponent = loaded
+		}
+		players[game.X] = network
+		players[game.O] = opponent
+		return players, hints(network), nil
+
+	default:
+		return nil, nil, fmt.Errorf("unknown mode %q", mode)
+	}
+}
+
+// hints returns the player as a hinter, or nil if there is no player or it can't rate moves
+func hints(player arena.Player) hinter {
+	if h, ok := player.(hinter); ok {
+		return h
+	}
+	return nil
+}
+
+// printHints shows the player's probability for each legal move, as a grid for boards
+func printHints(player hinter, state game.State, moves moveInput) {
+	probabilities := player.Probabilities(state)
+
+	fmt.Println("Network move probabilities:")
+	if board, ok := state.(game.Grid); ok {
+		for row := 0; row < board.Height(); row++ {
+			for col := 0; col < board.Width(); col++ {
+				if board.Get(row, col) != game.Empty {
+					fmt.Printf("   %s   ", playerToString(board.Get(row, col)))
+				} else {
+					fmt.Printf(" %5.1f%%", probabilities[neural.RowColToIndex(row, col, board.Width())]*100)
+				}
+			}
+			fmt.Println()
+		}
+		return
+	}
+
+	for _, move := range state.LegalMoves() {
+		fmt.Printf("  %s: %5.1f%%\n", moves.format(move), probabilities[move]*100)
+	}
+}
@@ -0,0 +1,221 @@
+package arena
+
+import (
+	"fmt"
+	"io"
+	"math"
+	"slices"
+
+	"github.com/ZachBeta/go_neural_network_learning/pkg/game"
+)
+
+// confidenceZ is the z-score for the 95% confidence intervals in reports
+const confidenceZ = 1.96
+
+// PlayGame plays a single game of g between two players and returns the winner
+// It returns game.Empty for a draw. A player that picks an illegal move forfeits the game.
+func PlayGame(g game.Game, x, o Player) game.Cell {
+	winner, _ := playGame(g, x, o)
+	return winner
+}
+
+// playGame plays a game like PlayGame and also returns the moves that were played
+func playGame(g game.Game, x, o Player) (game.Cell, []int) {
+	state := g.NewState()
+	var moves []int
+
+	for state.GetStatus() == game.InProgress {
+		mover, player := x, game.X
+		if state.GetCurrentPlayer() == game.O {
+			mover, player = o, game.O
+		}
+
+		move := mover.SelectMove(state)
+		moves = append(moves, move)
+		result, err := state.Apply(move)
+		if err != nil {
+			return opponent(player), moves
+		}
+		if result.Status != game.InProgress {
+			return result.Winner, moves
+		}
+	}
+
+	return game.Empty, moves
+}
+
+// opponent returns the other player
+func opponent(player game.Cell) game.Cell {
+	if player == game.X {
+		return game.O
+	}
+	return game.X
+}
+
+// Record counts the outcomes of a series of games from one player's point of view
+type Record struct {
+	Wins   int
+	Draws  int
+	Losses int
+}
+
+// Games returns the total number of games in the record
+func (r Record) Games() int {
+	return r.Wins + r.Draws + r.Losses
+}
+
+// Score returns the average score per game, counting a win as 1 and a draw as 0.5
+func (r Record) Score() float64 {
+	if r.Games() == 0 {
+		return 0
+	}
+	return (float64(r.Wins) + 0.5*float64(r.Draws)) / float64(r.Games())
+}
+
+// Add combines two records
+func (r Record) Add(other Record) Record {
+	return Record{
+		Wins:   r.Wins + other.Wins,
+		Draws:  r.Draws + other.Draws,
+		Losses: r.Losses + other.Losses,
+	}
+}
+
+// record adds the outcome of one game to the record
+func (r *Record) record(winner, side game.Cell) {
+	switch winner {
+	case game.Empty:
+		r.Draws++
+	case side:
+		r.Wins++
+	default:
+		r.Losses++
+	}
+}
+
+// MatchResult holds the outcome of a match against one opponent, split by side
+type MatchResult struct {
+	Opponent string
+	AsX      Record
+	AsO      Record
+
+	// Replayed is set when every game on each side repeated the moves of the first,
+	// as happens between two deterministic players. The games then sample nothing,
+	// so their rates have no confidence interval.
+	Replayed bool
+}
+
+// Total returns the combined record over both sides
+func (m MatchResult) Total() Record {
+	return m.AsX.Add(m.AsO)
+}
+
+// PlayMatch plays a number of games of g between player and opponent
+// Sides alternate every game, starting with player as X
+func PlayMatch(g game.Game, player, opponent Player, games int) MatchResult {
+	result := MatchResult{Opponent: opponent.Name()}
+
+	// The first game's moves on each side, and whether every later game repeated them
+	var first [2][]int
+	repeated := true
+	for i := 0; i < games; i++ {
+		var winner game.Cell
+		var moves []int
+		if i%2 == 0 {
+			winner, moves = playGame(g, player, opponent)
+			result.AsX.record(winner, game.X)
+		} else {
+			winner, moves = playGame(g, opponent, player)
+			result.AsO.record(winner, game.O)
+		}
+
+		if side := i % 2; i < 2 {
+			first[side] = moves
+		} else if !slices.Equal(moves, first[side]) {
+			repeated = false
+		}
+	}
+
+	// Each side needs a second game before a replay can be seen
+	result.Replayed = repeated && games >= 4
+	return result
+}
+
+// Report holds the results of a player against a set of opponents
+type Report struct {
+	Player  string
+	Matches []MatchResult
+}
+
+// Evaluate plays a match of g of the given length against each opponent
+func Evaluate(g game.Game, player Player, opponents []Player, gamesPerOpponent int) Report {
+	report := Report{Player: player.Name()}
+	for _, opp := range opponents {
+		report.Matches = append(report.Matches, PlayMatch(g, player, opp, gamesPerOpponent))
+	}
+	return report
+}
+
+// WilsonInterval returns the Wilson score interval for a binomial proportion
+// successes out of n trials, using the given z-score
+func WilsonInterval(successes, n int, z float64) (low, high float64) {
+	if n == 0 {
+		return 0, 1
+	}
+
+	p := float64(successes) / float64(n)
+	nf := float64(n)
+	denominator := 1 + z*z/nf
+	center := (p + z*z/(2*nf)) / denominator
+	margin := z * math.Sqrt(p*(1-p)/nf+z*z/(4*nf*nf)) / denominator
+
+	return math.Max(0, center-margin), math.Min(1, center+margin)
+}
+
+// Print writes the report as a table with 95% confidence intervals for each outcome
+// Replayed matches are shown without intervals, followed by a note.
+func (r Report) Print(w io.Writer) {
+	replayed := false
+	fmt.Fprintf(w, "Evaluation of %s\n", r.Player)
+	fmt.Fprintf(w, "%-12s %-5s %6s %22s %22s %22s %7s\n",
+		"Opponent", "Side", "Games", "Wins", "Draws", "Losses", "Score")
+
+	for _, match := range r.Matches {
+		rows := []struct {
+			side   string
+			record Record
+		}{
+			{"X", match.AsX},
+			{"O", match.AsO},
+			{"all", match.Total()},
+		}
+		interval := !match.Replayed
+		replayed = replayed || match.Replayed
+		for _, row := range rows {
+			fmt.Fprintf(w, "%-12s %-5s %6d %22s %22s %22s %6.1f%%\n",
+				match.Opponent, row.side, row.record.Games(),
+				formatRate(row.record.Wins, row.record.Games(), interval),
+				formatRate(row.record.Draws, row.record.Games(), interval),
+				formatRate(row.record.Losses, row.record.Games(), interval),
+				row.record.Score()*100)
+		}
+	}
+
+	if replayed {
+		fmt.Fprintln(w, "\nMatches without intervals replayed the same game on each side;")
+		fmt.Fprintln(w, "both players are deterministic, so more games would not change the rates.")
+	}
+}
+
+// formatRate formats a count as a percentage, with its confidence interval if interval is set
+func formatRate(count, games int, interval bool) string {
+	if games == 0 {
+		return "-"
+	}
+	if !interval {
+		return fmt.Sprintf("%5.1f%%", float64(count)/float64(games)*100)
+	}
+	low, high := WilsonInterval(count, games, confidenceZ)
+	return fmt.Sprintf("%5.1f%% [%4.1f-%5.1f]",
+		float64(count)/float64(games)*100, low*100, high*100)
+}
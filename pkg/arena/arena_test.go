@@ -0,0 +1,188 @@
+package arena
+
+import (
+	"math"
+	"math/rand/v2"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/ZachBeta/go_neural_network_learning/internal/gametest"
+	"github.com/ZachBeta/go_neural_network_learning/internal/utils"
+	"github.com/ZachBeta/go_neural_network_learning/pkg/connectfour"
+	"github.com/ZachBeta/go_neural_network_learning/pkg/game"
+	"github.com/ZachBeta/go_neural_network_learning/pkg/mcts"
+	"github.com/ZachBeta/go_neural_network_learning/pkg/neural"
+	"github.com/ZachBeta/go_neural_network_learning/pkg/solver"
+)
+
+func TestPerfectPlayerNeverLoses(t *testing.T) {
+	utils.SetLogLevel(utils.ERROR)
+	rng := rand.New(rand.NewPCG(1, 2))
+	perfect := NewPerfectPlayer(solver.New(), rng)
+
+	report := Evaluate(game.TicTacToe{}, perfect, ReferencePlayers(game.TicTacToe{}, rng), 20)
+	if len(report.Matches) != 4 {
+		t.Fatalf("expected 4 matches, got %d", len(report.Matches))
+	}
+
+	for _, match := range report.Matches {
+		total := match.Total()
+		if total.Games() != 20 || match.AsX.Games() != 10 || match.AsO.Games() != 10 {
+			t.Errorf("%s: expected 10 games per side, got X=%d O=%d", match.Opponent, match.AsX.Games(), match.AsO.Games())
+		}
+		if total.Losses != 0 {
+			t.Errorf("perfect player lost %d games against %s", total.Losses, match.Opponent)
+		}
+		if match.Opponent == "perfect" && total.Draws != 20 {
+			t.Errorf("perfect vs perfect should always draw, got %+v", total)
+		}
+	}
+
+	var out strings.Builder
+	report.Print(&out)
+	if !strings.Contains(out.String(), "heuristic") {
+		t.Errorf("report is missing the heuristic opponent:\n%s", out.String())
+	}
+}
+
+func TestHeuristicPlayer(t *testing.T) {
+	utils.SetLogLevel(utils.ERROR)
+	player := NewHeuristicPlayer(rand.New(rand.NewPCG(3, 4)))
+
+	// X to move can win at index 2
+	board := gametest.Board(t, 0, 3, 1, 4)
+	if move := player.SelectMove(board); move != 2 {
+		t.Errorf("SelectMove() = %d, want winning move 2", move)
+	}
+
+	// O to move must block at index 2
+	board = gametest.Board(t, 0, 4, 1)
+	if move := player.SelectMove(board); move != 2 {
+		t.Errorf("SelectMove() = %d, want blocking move 2", move)
+	}
+}
+
+func TestOtherGames(t *testing.T) {
+	utils.SetLogLevel(utils.ERROR)
+	rng := rand.New(rand.NewPCG(5, 6))
+
+	// Only the game-independent reference players take part
+	g := connectfour.Game{}
+	opponents := ReferencePlayers(g, rng)
+	if len(opponents) != 2 {
+		t.Fatalf("expected 2 reference players for Connect Four, got %d", len(opponents))
+	}
+
+	// A network sized for the game plays only legal moves
+	network := neural.NewNetwork(g.InputSize(), g.ActionSize())
+	report := Evaluate(g, NewNetworkPlayer("network", network), opponents, 10)
+	for _, match := range report.Matches {
+		if match.Total().Games() != 10 {
+			t.Errorf("%s: expected 10 games, got %d", match.Opponent, match.Total().Games())
+		}
+	}
+
+	// The perfect player forfeits games it cannot solve
+	perfect := NewPerfectPlayer(solver.New(), rng)
+	if winner := PlayGame(g, perfect, &FirstLegalPlayer{}); winner != game.O {
+		t.Errorf("PlayGame() = %v, want a forfeit win for O", winner)
+	}
+}
+
+func TestMCTSPlayer(t *testing.T) {
+	utils.SetLogLevel(utils.ERROR)
+	rng := rand.New(rand.NewPCG(7, 8))
+
+	config := mcts.DefaultConfig()
+	config.Simulations = 200
+	config.NoiseFraction = 0
+	player := NewMCTSPlayer("mcts", mcts.New(mcts.RolloutEvaluator{RNG: rng}, config, rng))
+
+	result := PlayMatch(game.TicTacToe{}, player, NewRandomPlayer(rng), 10)
+	if total := result.Total(); total.Losses > 0 || total.Wins < 5 {
+		t.Errorf("MCTS against random: %+v", total)
+	}
+}
+
+func TestLoadPlayer(t *testing.T) {
+	utils.SetLogLevel(utils.ERROR)
+	rng := rand.New(rand.NewPCG(1, 2))
+	dir := t.TempDir()
+
+	plain := filepath.Join(dir, "plain.json")
+	if err := neural.SaveModel(plain, neural.NewNetwork(9, 9), nil); err != nil {
+		t.Fatal(err)
+	}
+	policyValue := filepath.Join(dir, "best.json")
+	network, err := neural.NewPolicyValueNetwork([]int{9, 8}, 9, &neural.Sigmoid{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := neural.SavePolicyValueModel(policyValue, network, nil); err != nil {
+		t.Fatal(err)
+	}
+
+	if player, err := LoadPlayer(game.TicTacToe{}, plain, "plain", rng); err != nil {
+		t.Errorf("LoadPlayer(plain): %v", err)
+	} else if _, ok := player.(*NetworkPlayer); !ok {
+		t.Errorf("plain network loaded as %T, want *NetworkPlayer", player)
+	}
+
+	player, err := LoadPlayer(game.TicTacToe{}, policyValue, "best", rng)
+	if err != nil {
+		t.Fatalf("LoadPlayer(policy/value): %v", err)
+	}
+	mctsPlayer, ok := player.(*MCTSPlayer)
+	if !ok {
+		t.Fatalf("policy/value network loaded as %T, want *MCTSPlayer", player)
+	}
+	if move := mctsPlayer.SelectMove(game.NewBoard()); move < 0 || move > 8 {
+		t.Errorf("MCTS player chose move %d", move)
+	}
+
+	if _, err := LoadPlayer(connectfour.Game{}, policyValue, "best", rng); err == nil {
+		t.Error("LoadPlayer accepted a tic-tac-toe network for Connect Four")
+	}
+}
+
+func TestReplayedMatch(t *testing.T) {
+	utils.SetLogLevel(utils.ERROR)
+	rng := rand.New(rand.NewPCG(5, 6))
+
+	// Two first-legal players play the same game every time
+	replayed := PlayMatch(game.TicTacToe{}, &FirstLegalPlayer{}, &FirstLegalPlayer{}, 6)
+	if !replayed.Replayed {
+		t.Errorf("first-legal against itself should be a replay: %+v", replayed)
+	}
+	random := PlayMatch(game.TicTacToe{}, &FirstLegalPlayer{}, NewRandomPlayer(rng), 20)
+	if random.Replayed {
+		t.Errorf("games against a random player should vary: %+v", random)
+	}
+
+	var out strings.Builder
+	Report{Player: "first-legal", Matches: []MatchResult{replayed, random}}.Print(&out)
+	for _, line := range strings.Split(out.String(), "\n") {
+		if strings.HasPrefix(line, replayed.Opponent) && strings.Contains(line, "[") {
+			t.Errorf("replayed match printed with an interval: %q", line)
+		}
+		if strings.HasPrefix(line, random.Opponent) && !strings.Contains(line, "[") {
+			t.Errorf("random match printed without an interval: %q", line)
+		}
+	}
+	if !strings.Contains(out.String(), "deterministic") {
+		t.Errorf("report is missing the replay note:\n%s", out.String())
+	}
+}
+
+func TestWilsonInterval(t *testing.T) {
+	low, high := WilsonInterval(50, 100, 1.96)
+	if math.Abs(low-0.4038) > 1e-3 || math.Abs(high-0.5962) > 1e-3 {
+		t.Errorf("WilsonInterval(50, 100) = [%v, %v], want about [0.404, 0.596]", low, high)
+	}
+
+	low, high = WilsonInterval(0, 10, 1.96)
+	if low != 0 || high <= 0 {
+		t.Errorf("WilsonInterval(0, 10) = [%v, %v], want lower bound 0", low, high)
+	}
+}
@@ -0,0 +1,248 @@
+package arena
+
+import (
+	"errors"
+	"fmt"
+	"math/rand/v2"
+
+	"github.com/ZachBeta/go_neural_network_learning/pkg/game"
+	"github.com/ZachBeta/go_neural_network_learning/pkg/mcts"
+	"github.com/ZachBeta/go_neural_network_learning/pkg/neural"
+	"github.com/ZachBeta/go_neural_network_learning/pkg/solver"
+	"github.com/ZachBeta/go_neural_network_learning/pkg/strategy"
+)
+
+// Player chooses moves in an arena game
+type Player interface {
+	// Name returns a short name used in reports
+	Name() string
+
+	// SelectMove returns the move to play for the player to move
+	SelectMove(state game.State) int
+}
+
+// RandomPlayer plays a uniformly random legal move
+type RandomPlayer struct {
+	rng *rand.Rand
+}
+
+// NewRandomPlayer creates a random player
+func NewRandomPlayer(rng *rand.Rand) *RandomPlayer {
+	return &RandomPlayer{rng: rng}
+}
+
+// Name returns the name of the player
+func (p *RandomPlayer) Name() string {
+	return "random"
+}
+
+// SelectMove returns a random legal move
+func (p *RandomPlayer) SelectMove(state game.State) int {
+	moves := state.LegalMoves()
+	if len(moves) == 0 {
+		return -1
+	}
+	return moves[p.rng.IntN(len(moves))]
+}
+
+// FirstLegalPlayer always plays the lowest-indexed empty cell
+type FirstLegalPlayer struct{}
+
+// Name returns the name of the player
+func (p *FirstLegalPlayer) Name() string {
+	return "first-legal"
+}
+
+// SelectMove returns the first legal move
+func (p *FirstLegalPlayer) SelectMove(state game.State) int {
+	moves := state.LegalMoves()
+	if len(moves) == 0 {
+		return -1
+	}
+	return moves[0]
+}
+
+// HeuristicPlayer follows simple tic-tac-toe rules of thumb:
+// win if possible, otherwise block the opponent's win, otherwise prefer
+// the center, then corners, then edges
+// In any other game it plays a random legal move
+type HeuristicPlayer struct {
+	rng *rand.Rand
+}
+
+// NewHeuristicPlayer creates a heuristic player
+// Ties between equally good moves are broken with rng
+func NewHeuristicPlayer(rng *rand.Rand) *HeuristicPlayer {
+	return &HeuristicPlayer{rng: rng}
+}
+
+// Name returns the name of the player
+func (p *HeuristicPlayer) Name() string {
+	return "heuristic"
+}
+
+// SelectMove returns the move preferred by the heuristic
+func (p *HeuristicPlayer) SelectMove(state game.State) int {
+	moves := state.LegalMoves()
+	if len(moves) == 0 {
+		return -1
+	}
+	board, ok := state.(*game.Board)
+	if !ok {
+		return moves[p.rng.IntN(len(moves))]
+	}
+
+	rules := []func(move int) bool{
+		func(move int) bool { return strategy.IsWinningMove(board, move) },
+		func(move int) bool { return strategy.IsBlockingMove(board, move) },
+		func(move int) bool { return move == 4 },
+		func(move int) bool { return move == 0 || move == 2 || move == 6 || move == 8 },
+	}
+
+	for _, rule := range rules {
+		candidates := make([]int, 0, len(moves))
+		for _, move := range moves {
+			if rule(move) {
+				candidates = append(candidates, move)
+			}
+		}
+		if len(candidates) > 0 {
+			return candidates[p.rng.IntN(len(candidates))]
+		}
+	}
+
+	return moves[p.rng.IntN(len(moves))]
+}
+
+// PerfectPlayer plays optimally using the solver
+// The solver only knows tic-tac-toe; in other games the player forfeits
+type PerfectPlayer struct {
+	player *solver.PerfectPlayer
+}
+
+// NewPerfectPlayer creates a perfect player that picks randomly among optimal moves
+func NewPerfectPlayer(s *solver.Solver, rng *rand.Rand) *PerfectPlayer {
+	return &PerfectPlayer{player: solver.NewPerfectPlayer(s, rng)}
+}
+
+// Name returns the name of the player
+func (p *PerfectPlayer) Name() string {
+	return "perfect"
+}
+
+// SelectMove returns an optimal move, or -1 if the state is not a tic-tac-toe board
+func (p *PerfectPlayer) SelectMove(state game.State) int {
+	board, ok := state.(*game.Board)
+	if !ok {
+		return -1
+	}
+	return p.player.SelectMove(board)
+}
+
+// NetworkPlayer plays the legal move its network rates highest
+type NetworkPlayer struct {
+	name    string
+	network *neural.Network
+}
+
+// NewNetworkPlayer creates a player backed by a neural network
+func NewNetworkPlayer(name string, network *neural.Network) *NetworkPlayer {
+	return &NetworkPlayer{
+		name:    name,
+		network: network,
+	}
+}
+
+// Name returns the name of the player
+func (p *NetworkPlayer) Name() string {
+	return p.name
+}
+
+// SelectMove returns the legal move with the highest probability
+func (p *NetworkPlayer) SelectMove(state game.State) int {
+	probabilities := neural.PredictStateProbabilities(p.network, state)
+	return neural.SelectBestLegalMove(probabilities, neural.StateMoveMask(state))
+}
+
+// Probabilities returns the network's probability for each move of the position
+func (p *NetworkPlayer) Probabilities(state game.State) []float64 {
+	return neural.PredictStateProbabilities(p.network, state)
+}
+
+// MCTSPlayer plays the most visited move of a Monte Carlo Tree Search
+type MCTSPlayer struct {
+	name   string
+	search *mcts.Search
+}
+
+// NewMCTSPlayer creates a player that searches every position before moving
+func NewMCTSPlayer(name string, search *mcts.Search) *MCTSPlayer {
+	return &MCTSPlayer{
+		name:   name,
+		search: search,
+	}
+}
+
+// Name returns the name of the player
+func (p *MCTSPlayer) Name() string {
+	return p.name
+}
+
+// SelectMove searches the position and returns the most visited move
+func (p *MCTSPlayer) SelectMove(state game.State) int {
+	return p.search.Run(state).BestMove()
+}
+
+// Probabilities searches the position and returns the visit share of each move
+func (p *MCTSPlayer) Probabilities(state game.State) []float64 {
+	return p.search.Run(state).Policy(1)
+}
+
+// LoadPlayer loads the model at path as a player of g
+// A plain network plays its highest rated move; a policy/value network, as trained
+// with neural_train -alphazero, guides a search without root noise.
+func LoadPlayer(g game.Game, path, name string, rng *rand.Rand) (Player, error) {
+	network, err := neural.LoadNetwork(path)
+	if errors.Is(err, neural.ErrPolicyValueModel) {
+		return loadMCTSPlayer(g, path, name, rng)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	if err := neural.CheckNetworkFits(network, g); err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	return NewNetworkPlayer(name, network), nil
+}
+
+// loadMCTSPlayer loads the policy/value network at path as an MCTS player of g
+func loadMCTSPlayer(g game.Game, path, name string, rng *rand.Rand) (*MCTSPlayer, error) {
+	network, _, err := neural.LoadPolicyValueModel(path)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	if err := neural.CheckNetworkFits(network, g); err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+
+	// Noise is for exploring in self-play; a player should play its best
+	config := mcts.DefaultConfig()
+	config.NoiseFraction = 0
+	return NewMCTSPlayer(name, mcts.New(mcts.NetworkEvaluator{Network: network}, config, rng)), nil
+}
+
+// ReferencePlayers returns the fixed baseline opponents for a game
+// Every game gets random and first-legal; tic-tac-toe adds heuristic and perfect
+func ReferencePlayers(g game.Game, rng *rand.Rand) []Player {
+	players := []Player{
+		NewRandomPlayer(rng),
+		&FirstLegalPlayer{},
+	}
+	if _, ok := g.NewState().(*game.Board); ok {
+		players = append(players,
+			NewHeuristicPlayer(rng),
+			NewPerfectPlayer(solver.New(), rng),
+		)
+	}
+	return players
+}
@@ -0,0 +1,223 @@
+package connectfour
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/ZachBeta/go_neural_network_learning/pkg/game"
+)
+
+// Board dimensions and the number of discs in a row needed to win
+const (
+	Columns   = 7
+	Rows      = 6
+	WinLength = 4
+)
+
+// InputSize is the length of the network input produced by Encode:
+// one plane for the discs of the player to move and one for the opponent's
+const InputSize = 2 * Rows * Columns
+
+// Board is a game.State
+var _ game.State = (*Board)(nil)
+
+// Game is the game.Game for Connect Four
+type Game struct{}
+
+// Name returns the name of the game
+func (Game) Name() string {
+	return "connectfour"
+}
+
+// NewState returns an empty board
+func (Game) NewState() game.State {
+	return NewBoard()
+}
+
+// InputSize returns the length of the vector returned by Encode
+func (Game) InputSize() int {
+	return InputSize
+}
+
+// ActionSize returns the number of columns
+func (Game) ActionSize() int {
+	return Columns
+}
+
+// ColumnFullError is returned when a disc is dropped into a full column
+type ColumnFullError struct {
+	Col int
+}
+
+func (e *ColumnFullError) Error() string {
+	return fmt.Sprintf("column %d is full", e.Col)
+}
+
+// InvalidColumnError is returned when a disc is dropped outside the board
+type InvalidColumnError struct {
+	Col int
+}
+
+func (e *InvalidColumnError) Error() string {
+	return fmt.Sprintf("invalid column %d", e.Col)
+}
+
+// Board is a Connect Four board: discs are dropped into one of 7 columns
+// and fall to the lowest empty row. The first player to connect 4 wins.
+// Row 0 is the top row; moves are column indices (0-6).
+type Board struct {
+	grid    *game.MNKBoard
+	heights [Columns]int
+	// moves holds the column of every move, oldest first
+	moves []int
+}
+
+// NewBoard creates an empty Connect Four board with X to move
+func NewBoard() *Board {
+	grid, err := game.NewMNKBoard(Columns, Rows, WinLength)
+	if err != nil {
+		// The dimensions are constants, so this cannot happen
+		panic(err)
+	}
+	return &Board{grid: grid}
+}
+
+// Get returns the cell value at the given position
+func (b *Board) Get(row, col int) game.Cell {
+	return b.grid.Get(row, col)
+}
+
+// GetCurrentPlayer returns the player to move
+func (b *Board) GetCurrentPlayer() game.Cell {
+	return b.grid.GetCurrentPlayer()
+}
+
+// GetStatus returns the current game status
+func (b *Board) GetStatus() game.GameStatus {
+	return b.grid.GetStatus()
+}
+
+// Winner returns the player who connected four and the cell indices of the winning run
+// It returns game.Empty and nil if nobody has won.
+func (b *Board) Winner() (game.Cell, []int) {
+	return b.grid.Winner()
+}
+
+// ColumnHeight returns the number of discs in a column
+func (b *Board) ColumnHeight(col int) int {
+	return b.heights[col]
+}
+
+// Drop drops a disc for the current player into a column
+// A rejected move leaves the board unchanged and returns a *game.GameOverError,
+// *InvalidColumnError or *ColumnFullError.
+func (b *Board) Drop(col int) (game.MoveResult, error) {
+	if b.GetStatus() != game.InProgress {
+		return game.MoveResult{}, &game.GameOverError{Status: b.GetStatus()}
+	}
+	if col < 0 || col >= Columns {
+		return game.MoveResult{}, &InvalidColumnError{Col: col}
+	}
+	if b.heights[col] == Rows {
+		return game.MoveResult{}, &ColumnFullError{Col: col}
+	}
+
+	result, err := b.grid.MakeMove(Rows-1-b.heights[col], col)
+	if err != nil {
+		return result, err
+	}
+	b.heights[col]++
+	b.moves = append(b.moves, col)
+	return result, nil
+}
+
+// Apply drops a disc into the column given by move; it makes Board a game.State
+func (b *Board) Apply(move int) (game.MoveResult, error) {
+	return b.Drop(move)
+}
+
+// ActionSize returns the number of columns
+func (b *Board) ActionSize() int {
+	return Columns
+}
+
+// CloneState returns a copy of the board as a game.State
+func (b *Board) CloneState() game.State {
+	return b.Clone()
+}
+
+// Undo takes back the last move
+// It returns false if there is no move to undo.
+func (b *Board) Undo() bool {
+	if len(b.moves) == 0 {
+		return false
+	}
+	col := b.moves[len(b.moves)-1]
+	b.moves = b.moves[:len(b.moves)-1]
+	b.heights[col]--
+	return b.grid.UndoMove()
+}
+
+// LastMove returns the column of the last move, or -1 if no move has been made
+func (b *Board) LastMove() int {
+	if len(b.moves) == 0 {
+		return -1
+	}
+	return b.moves[len(b.moves)-1]
+}
+
+// LegalMoves returns the columns that still have room, or none once the game is over
+func (b *Board) LegalMoves() []int {
+	moves := make([]int, 0, Columns)
+	if b.GetStatus() != game.InProgress {
+		return moves
+	}
+	for col := 0; col < Columns; col++ {
+		if b.heights[col] < Rows {
+			moves = append(moves, col)
+		}
+	}
+	return moves
+}
+
+// Clone creates a deep copy of the board
+func (b *Board) Clone() *Board {
+	return &Board{
+		grid:    b.grid.Clone(),
+		heights: b.heights,
+		moves:   append([]int(nil), b.moves...),
+	}
+}
+
+// String returns a string representation of the board with column numbers underneath
+func (b *Board) String() string {
+	var result strings.Builder
+	result.WriteString(b.grid.String())
+	for col := 0; col < Columns; col++ {
+		fmt.Fprintf(&result, "%d", col)
+	}
+	result.WriteByte('\n')
+	return result.String()
+}
+
+// Encode converts the board to a neural network input vector
+// The first Rows*Columns values mark the discs of the player to move and the
+// rest mark the opponent's, so the network always sees the position from the
+// side of the player it is choosing a move for
+func (b *Board) Encode() []float64 {
+	input := make([]float64, InputSize)
+	player := b.GetCurrentPlayer()
+	for row := 0; row < Rows; row++ {
+		for col := 0; col < Columns; col++ {
+			index := row*Columns + col
+			switch b.Get(row, col) {
+			case player:
+				input[index] = 1.0
+			case game.Empty:
+			default:
+				input[Rows*Columns+index] = 1.0
+			}
+		}
+	}
+	return input
+}
@@ -0,0 +1,112 @@
+package connectfour
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/ZachBeta/go_neural_network_learning/internal/utils"
+	"github.com/ZachBeta/go_neural_network_learning/pkg/game"
+)
+
+// boardFromMoves drops discs into the given columns on a new board
+func boardFromMoves(t *testing.T, cols ...int) *Board {
+	t.Helper()
+	board := NewBoard()
+	for _, col := range cols {
+		if _, err := board.Drop(col); err != nil {
+			t.Fatalf("drop in column %d rejected: %v", col, err)
+		}
+	}
+	return board
+}
+
+func TestDrop(t *testing.T) {
+	utils.SetLogLevel(utils.ERROR)
+
+	board := boardFromMoves(t, 3, 3, 4)
+	if board.Get(Rows-1, 3) != game.X || board.Get(Rows-2, 3) != game.O || board.Get(Rows-1, 4) != game.X {
+		t.Errorf("discs did not fall to the bottom:\n%s", board)
+	}
+	if board.ColumnHeight(3) != 2 || board.LastMove() != 4 || board.GetCurrentPlayer() != game.O {
+		t.Errorf("height %d, last move %d, player %v", board.ColumnHeight(3), board.LastMove(), board.GetCurrentPlayer())
+	}
+
+	// Fill column 0
+	full := boardFromMoves(t, 0, 0, 0, 0, 0, 0)
+	var fullErr *ColumnFullError
+	if _, err := full.Drop(0); !errors.As(err, &fullErr) {
+		t.Errorf("expected ColumnFullError, got %v", err)
+	}
+	if moves := full.LegalMoves(); len(moves) != Columns-1 || moves[0] != 1 {
+		t.Errorf("LegalMoves() = %v, want columns 1-6", moves)
+	}
+	var invalid *InvalidColumnError
+	if _, err := full.Drop(Columns); !errors.As(err, &invalid) {
+		t.Errorf("expected InvalidColumnError, got %v", err)
+	}
+
+	// Undo lifts the top disc off the column
+	if !board.Undo() || board.ColumnHeight(4) != 0 || board.GetCurrentPlayer() != game.X || board.LastMove() != 3 {
+		t.Errorf("undo failed:\n%s", board)
+	}
+}
+
+func TestWinner(t *testing.T) {
+	utils.SetLogLevel(utils.ERROR)
+
+	tests := []struct {
+		name   string
+		moves  []int
+		winner game.Cell
+	}{
+		{"horizontal", []int{0, 0, 1, 1, 2, 2, 3}, game.X},
+		{"vertical", []int{0, 1, 0, 1, 0, 1, 6, 1}, game.O},
+		{"diagonal", []int{0, 1, 1, 2, 2, 3, 2, 3, 3, 6, 3}, game.X},
+		{"anti-diagonal", []int{6, 5, 5, 4, 4, 3, 4, 3, 3, 0, 3}, game.X},
+		{"no winner yet", []int{0, 1, 2, 3}, game.Empty},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			board := boardFromMoves(t, tt.moves...)
+			winner, line := board.Winner()
+			if winner != tt.winner {
+				t.Errorf("Winner() = %v, want %v\n%s", winner, tt.winner, board)
+			}
+			if tt.winner != game.Empty {
+				if board.GetStatus() != game.Won || len(line) != WinLength || len(board.LegalMoves()) != 0 {
+					t.Errorf("status %v, line %v, legal moves %v", board.GetStatus(), line, board.LegalMoves())
+				}
+			}
+		})
+	}
+}
+
+func TestEncode(t *testing.T) {
+	utils.SetLogLevel(utils.ERROR)
+
+	// X in column 3, O on top of it; X to move
+	board := boardFromMoves(t, 3, 3)
+	input := board.Encode()
+	if len(input) != InputSize {
+		t.Fatalf("Encode() has %d values, want %d", len(input), InputSize)
+	}
+	own := (Rows-1)*Columns + 3
+	opponent := Rows*Columns + (Rows-2)*Columns + 3
+	if input[own] != 1 || input[opponent] != 1 {
+		t.Errorf("expected X's disc in the own plane and O's in the opponent plane")
+	}
+
+	// After X moves the planes swap
+	board.Drop(0)
+	input = board.Encode()
+	if input[Rows*Columns+own] != 1 || input[(Rows-2)*Columns+3] != 1 {
+		t.Errorf("planes did not follow the player to move")
+	}
+
+	clone := board.Clone()
+	clone.Drop(6)
+	if board.ColumnHeight(6) != 0 || board.GetCurrentPlayer() != game.O {
+		t.Errorf("clone shares state with the original")
+	}
+}
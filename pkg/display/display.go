@@ -61,7 +61,7 @@ func DisplayStatistics(gameNum, totalGames int, xWins, oWins, draws int, epsilon
 	fmt.Printf("  Draws: %.1f%% (%d draws)\n", drawRate, draws)
 	fmt.Printf("\nTraining Parameters:\n")
 	fmt.Printf("  Epsilon: %.3f\n", epsilon)
-	fmt.Println("==========================================\n")
+	fmt.Printf("==========================================\n\n")
 
 	// Wait for 5 seconds
 	time.Sleep(5 * time.Second)
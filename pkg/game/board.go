@@ -1,6 +1,9 @@
 package game
 
 import (
+	"encoding/json"
+	"fmt"
+
 	"github.com/ZachBeta/go_neural_network_learning/internal/utils"
 )
 
@@ -22,11 +25,22 @@ const (
 	Draw
 )
 
+// winLines lists the cell indices of every row, column and diagonal
+var winLines = [8][3]int{
+	{0, 1, 2}, {3, 4, 5}, {6, 7, 8}, // rows
+	{0, 3, 6}, {1, 4, 7}, {2, 5, 8}, // columns
+	{0, 4, 8}, {2, 4, 6}, // diagonals
+}
+
 // Board represents the game board using a flat array
 type Board struct {
 	cells         [9]Cell
 	currentPlayer Cell
 	status        GameStatus
+	// history holds the cell index (0-8) of every move made with MakeMove, oldest first
+	history []int
+	// hash is the Zobrist hash of the position, kept up to date by Set and SwitchPlayer
+	hash uint64
 }
 
 // NewBoard creates a new empty board
@@ -54,7 +68,9 @@ func (b *Board) Set(row, col int, value Cell) bool {
 		utils.Debug("Attempted to set invalid position: row=%d, col=%d", row, col)
 		return false
 	}
-	b.cells[row*3+col] = value
+	index := row*3 + col
+	b.hash ^= zobristCell(index, b.cells[index]) ^ zobristCell(index, value)
+	b.cells[index] = value
 	utils.Debug("Set position (%d,%d) to %v", row, col, value)
 	return true
 }
@@ -90,6 +106,7 @@ func (b *Board) SwitchPlayer() {
 	} else {
 		b.currentPlayer = X
 	}
+	b.hash ^= zobristSide
 	utils.Debug("Switched current player to %v", b.currentPlayer)
 }
 
@@ -112,63 +129,158 @@ func (b *Board) GetStatus() GameStatus {
 	return b.status
 }
 
-// MakeMove attempts to make a move at the specified position
-func (b *Board) MakeMove(row, col int) bool {
+// MoveResult describes the outcome of an accepted move
+type MoveResult struct {
+	// Player is the player who made the move
+	Player Cell
+	// Index is the cell index (0-8) of the move
+	Index int
+	// Status is the game status after the move
+	Status GameStatus
+	// Winner is the player who won with this move, or Empty
+	Winner Cell
+	// Line holds the cell indices of the winning line, or nil
+	Line []int
+}
+
+// GameOverError is returned when a move is attempted after the game has ended
+type GameOverError struct {
+	Status GameStatus
+}
+
+func (e *GameOverError) Error() string {
+	return fmt.Sprintf("game is over (status=%v)", e.Status)
+}
+
+// InvalidPositionError is returned when a move is outside the board
+type InvalidPositionError struct {
+	Row, Col int
+}
+
+func (e *InvalidPositionError) Error() string {
+	return fmt.Sprintf("invalid position (%d,%d)", e.Row, e.Col)
+}
+
+// CellOccupiedError is returned when a move targets a cell that is already taken
+type CellOccupiedError struct {
+	Row, Col int
+	Occupant Cell
+}
+
+func (e *CellOccupiedError) Error() string {
+	return fmt.Sprintf("position (%d,%d) is already occupied by %c", e.Row, e.Col, cellToByte(e.Occupant))
+}
+
+// MakeMove makes a move for the current player at the specified position
+// The game status is updated as part of the move, so callers don't need CheckWinner.
+// A rejected move leaves the board unchanged and returns a *GameOverError,
+// *InvalidPositionError or *CellOccupiedError.
+func (b *Board) MakeMove(row, col int) (MoveResult, error) {
 	if b.status != InProgress {
 		utils.Debug("Move rejected: game is not in progress (status=%v)", b.status)
-		return false
+		return MoveResult{}, &GameOverError{Status: b.status}
+	}
+	if !isValidPosition(row, col) {
+		utils.Debug("Move rejected: invalid position (row=%d, col=%d)", row, col)
+		return MoveResult{}, &InvalidPositionError{Row: row, Col: col}
+	}
+	if occupant := b.Get(row, col); occupant != Empty {
+		utils.Debug("Move rejected: position already occupied (row=%d, col=%d)", row, col)
+		return MoveResult{}, &CellOccupiedError{Row: row, Col: col, Occupant: occupant}
+	}
+
+	player := b.currentPlayer
+	utils.Info("Player %v making move at position (%d,%d)", player, row, col)
+	b.Set(row, col, player)
+	b.history = append(b.history, row*3+col)
+	b.SwitchPlayer()
+	b.CheckWinner()
+
+	result := MoveResult{
+		Player: player,
+		Index:  row*3 + col,
+		Status: b.status,
+	}
+	if b.status == Won {
+		result.Winner, result.Line = b.Winner()
 	}
-	if !isValidPosition(row, col) || b.Get(row, col) != Empty {
-		utils.Debug("Move rejected: invalid position or position already occupied (row=%d, col=%d)", row, col)
+	return result, nil
+}
+
+// UndoMove takes back the last move made with MakeMove
+// The player who made it is to move again and the game is back in progress.
+// It returns false if there is no move to undo.
+func (b *Board) UndoMove() bool {
+	if len(b.history) == 0 {
+		utils.Debug("Undo rejected: no moves in history")
 		return false
 	}
-	utils.Info("Player %v making move at position (%d,%d)", b.currentPlayer, row, col)
-	b.Set(row, col, b.currentPlayer)
+	last := b.history[len(b.history)-1]
+	b.history = b.history[:len(b.history)-1]
+
+	b.Set(last/3, last%3, Empty)
 	b.SwitchPlayer()
+	b.UpdateStatus(InProgress)
+	utils.Debug("Undid move at index %d", last)
 	return true
 }
 
-// CheckWinner checks for a winner and updates the game status
-func (b *Board) CheckWinner() {
-	// Check rows
-	for i := 0; i < 3; i++ {
-		if b.Get(i, 0) != Empty && b.Get(i, 0) == b.Get(i, 1) && b.Get(i, 1) == b.Get(i, 2) {
-			utils.Info("Winner detected: horizontal win in row %d", i)
-			b.UpdateStatus(Won)
-			return
-		}
+// LastMove returns the cell index (0-8) of the last move, or -1 if no move has been made
+func (b *Board) LastMove() int {
+	if len(b.history) == 0 {
+		return -1
 	}
+	return b.history[len(b.history)-1]
+}
 
-	// Check columns
-	for j := 0; j < 3; j++ {
-		if b.Get(0, j) != Empty && b.Get(0, j) == b.Get(1, j) && b.Get(1, j) == b.Get(2, j) {
-			utils.Info("Winner detected: vertical win in column %d", j)
-			b.UpdateStatus(Won)
-			return
+// History returns the cell indices of all moves made so far, oldest first
+func (b *Board) History() []int {
+	return append([]int(nil), b.history...)
+}
+
+// EmptyCells returns the indices (0-8) of all empty cells, whether or not the game is over
+func (b *Board) EmptyCells() []int {
+	cells := make([]int, 0, 9)
+	for i, cell := range b.cells {
+		if cell == Empty {
+			cells = append(cells, i)
 		}
 	}
+	return cells
+}
 
-	// Check diagonals
-	if b.Get(0, 0) != Empty && b.Get(0, 0) == b.Get(1, 1) && b.Get(1, 1) == b.Get(2, 2) {
-		utils.Info("Winner detected: diagonal win (top-left to bottom-right)")
-		b.UpdateStatus(Won)
-		return
+// LegalMoves returns the indices (0-8) of the cells the current player may play
+// There are no legal moves once the game is over.
+func (b *Board) LegalMoves() []int {
+	if b.status != InProgress {
+		return []int{}
+	}
+	return b.EmptyCells()
+}
+
+// Winner returns the player who has three in a row and the indices of that line
+// It returns Empty and nil if nobody has won.
+func (b *Board) Winner() (Cell, []int) {
+	for _, line := range winLines {
+		first := b.cells[line[0]]
+		if first != Empty && first == b.cells[line[1]] && first == b.cells[line[2]] {
+			return first, []int{line[0], line[1], line[2]}
+		}
 	}
-	if b.Get(0, 2) != Empty && b.Get(0, 2) == b.Get(1, 1) && b.Get(1, 1) == b.Get(2, 0) {
-		utils.Info("Winner detected: diagonal win (top-right to bottom-left)")
+	return Empty, nil
+}
+
+// CheckWinner checks for a winner and updates the game status
+// MakeMove already does this; it is only needed for boards set up with Set
+func (b *Board) CheckWinner() {
+	if winner, line := b.Winner(); winner != Empty {
+		utils.Info("Winner detected: %v wins on cells %v", winner, line)
 		b.UpdateStatus(Won)
 		return
 	}
 
 	// Check for draw
-	isFull := true
-	for i := 0; i < 9; i++ {
-		if b.cells[i] == Empty {
-			isFull = false
-			break
-		}
-	}
-	if isFull {
+	if len(b.EmptyCells()) == 0 {
 		utils.Info("Game ended in a draw")
 		b.UpdateStatus(Draw)
 	}
@@ -183,5 +295,96 @@ func (b *Board) Clone() *Board {
 	}
 	clone.currentPlayer = b.currentPlayer
 	clone.status = b.status
+	clone.history = append([]int(nil), b.history...)
+	clone.hash = b.hash
 	return clone
 }
+
+// boardJSON is the serialized form of a Board
+type boardJSON struct {
+	// Cells holds one character per cell in row-major order: '.', 'X' or 'O'
+	Cells         string     `json:"cells"`
+	CurrentPlayer string     `json:"current_player"`
+	Status        GameStatus `json:"status"`
+	// History holds the moves made so far, oldest first
+	History []int `json:"history,omitempty"`
+}
+
+// MarshalJSON encodes the board as JSON
+func (b *Board) MarshalJSON() ([]byte, error) {
+	cells := make([]byte, 9)
+	for i, cell := range b.cells {
+		cells[i] = cellToByte(cell)
+	}
+	return json.Marshal(boardJSON{
+		Cells:         string(cells),
+		CurrentPlayer: string(cellToByte(b.currentPlayer)),
+		Status:        b.status,
+		History:       b.history,
+	})
+}
+
+// UnmarshalJSON decodes a board previously encoded with MarshalJSON
+func (b *Board) UnmarshalJSON(data []byte) error {
+	var decoded boardJSON
+	if err := json.Unmarshal(data, &decoded); err != nil {
+		return err
+	}
+	if len(decoded.Cells) != 9 {
+		return fmt.Errorf("board must have 9 cells, got %d", len(decoded.Cells))
+	}
+
+	for i := 0; i < 9; i++ {
+		cell, err := byteToCell(decoded.Cells[i])
+		if err != nil {
+			return err
+		}
+		b.cells[i] = cell
+	}
+
+	if len(decoded.CurrentPlayer) != 1 {
+		return fmt.Errorf("invalid current player %q", decoded.CurrentPlayer)
+	}
+	player, err := byteToCell(decoded.CurrentPlayer[0])
+	if err != nil || player == Empty {
+		return fmt.Errorf("invalid current player %q", decoded.CurrentPlayer)
+	}
+	b.currentPlayer = player
+	b.status = decoded.Status
+
+	for _, move := range decoded.History {
+		if move < 0 || move > 8 || b.cells[move] == Empty {
+			return fmt.Errorf("history move %d does not match the cells", move)
+		}
+	}
+	b.history = decoded.History
+	b.hash = b.computeHash()
+
+	return nil
+}
+
+// cellToByte converts a cell to the character used by String
+func cellToByte(cell Cell) byte {
+	switch cell {
+	case X:
+		return 'X'
+	case O:
+		return 'O'
+	default:
+		return '.'
+	}
+}
+
+// byteToCell converts a character produced by cellToByte back to a cell
+func byteToCell(c byte) (Cell, error) {
+	switch c {
+	case 'X':
+		return X, nil
+	case 'O':
+		return O, nil
+	case '.':
+		return Empty, nil
+	default:
+		return Empty, fmt.Errorf("invalid cell character %q", c)
+	}
+}
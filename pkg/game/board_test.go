@@ -1,6 +1,8 @@
 package game
 
 import (
+	"encoding/json"
+	"errors"
 	"testing"
 )
 
@@ -49,8 +51,12 @@ func TestMakeMove(t *testing.T) {
 	board := NewBoard()
 
 	// Test valid move
-	if !board.MakeMove(0, 0) {
-		t.Error("Failed to make valid move")
+	result, err := board.MakeMove(0, 0)
+	if err != nil {
+		t.Fatalf("Failed to make valid move: %v", err)
+	}
+	if result.Player != X || result.Index != 0 || result.Status != InProgress || result.Winner != Empty {
+		t.Errorf("unexpected result %+v", result)
 	}
 	if board.Get(0, 0) != X {
 		t.Error("Board not updated correctly after valid move")
@@ -60,12 +66,63 @@ func TestMakeMove(t *testing.T) {
 	}
 
 	// Test invalid move
-	if board.MakeMove(0, 0) {
-		t.Error("Should not make move at occupied position")
+	var occupied *CellOccupiedError
+	if _, err := board.MakeMove(0, 0); !errors.As(err, &occupied) || occupied.Occupant != X {
+		t.Errorf("expected CellOccupiedError, got %v", err)
 	}
 	if board.Get(0, 0) != X {
 		t.Error("Board should not change after invalid move")
 	}
+
+	var invalid *InvalidPositionError
+	if _, err := board.MakeMove(3, 0); !errors.As(err, &invalid) {
+		t.Errorf("expected InvalidPositionError, got %v", err)
+	}
+	if board.GetCurrentPlayer() != O {
+		t.Error("Player should not change after invalid move")
+	}
+}
+
+func TestMakeMoveEndsGame(t *testing.T) {
+	tests := []struct {
+		name   string
+		moves  []int
+		status GameStatus
+		winner Cell
+	}{
+		{"X wins on the diagonal", []int{0, 1, 4, 2, 8}, Won, X},
+		{"O wins on a column", []int{0, 1, 3, 4, 8, 7}, Won, O},
+		{"draw", []int{0, 1, 2, 4, 3, 5, 7, 6, 8}, Draw, Empty},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			board := NewBoard()
+			var result MoveResult
+			for _, move := range tt.moves {
+				var err error
+				result, err = board.MakeMove(move/3, move%3)
+				if err != nil {
+					t.Fatalf("move %d rejected: %v", move, err)
+				}
+			}
+			if result.Status != tt.status || board.GetStatus() != tt.status {
+				t.Errorf("expected status %v, got result %v and board %v", tt.status, result.Status, board.GetStatus())
+			}
+			if result.Winner != tt.winner {
+				t.Errorf("expected winner %v, got %v", tt.winner, result.Winner)
+			}
+			if tt.winner != Empty && len(result.Line) != 3 {
+				t.Errorf("expected a winning line, got %v", result.Line)
+			}
+
+			// The status check comes first, so even an occupied cell reports the game is over
+			var over *GameOverError
+			if _, err := board.MakeMove(0, 0); !errors.As(err, &over) {
+				t.Errorf("expected GameOverError, got %v", err)
+			}
+		})
+	}
 }
 
 func TestCheckWinner(t *testing.T) {
@@ -187,3 +244,273 @@ func TestCheckWinner(t *testing.T) {
 		})
 	}
 }
+
+func TestBoardJSON(t *testing.T) {
+	board := NewBoard()
+	board.MakeMove(0, 0)
+	board.MakeMove(1, 1)
+	board.MakeMove(2, 2)
+
+	data, err := json.Marshal(board)
+	if err != nil {
+		t.Fatalf("Marshal returned error: %v", err)
+	}
+
+	decoded := &Board{}
+	if err := json.Unmarshal(data, decoded); err != nil {
+		t.Fatalf("Unmarshal returned error: %v", err)
+	}
+
+	if decoded.String() != board.String() {
+		t.Errorf("Expected:\n%s\nGot:\n%s", board.String(), decoded.String())
+	}
+	if decoded.GetCurrentPlayer() != board.GetCurrentPlayer() {
+		t.Errorf("expected current player %v, got %v", board.GetCurrentPlayer(), decoded.GetCurrentPlayer())
+	}
+	if decoded.GetStatus() != board.GetStatus() {
+		t.Errorf("expected status %v, got %v", board.GetStatus(), decoded.GetStatus())
+	}
+	if decoded.LastMove() != 8 || len(decoded.History()) != 3 {
+		t.Errorf("expected history [0 4 8], got %v", decoded.History())
+	}
+
+	if err := json.Unmarshal([]byte(`{"cells":"XX","current_player":"X"}`), decoded); err == nil {
+		t.Error("expected error for malformed board")
+	}
+}
+
+func TestLegalMovesAndUndo(t *testing.T) {
+	board := NewBoard()
+	if len(board.LegalMoves()) != 9 || board.LastMove() != -1 {
+		t.Fatalf("new board: legal moves %v, last move %d", board.LegalMoves(), board.LastMove())
+	}
+	if board.UndoMove() {
+		t.Error("UndoMove should fail on a new board")
+	}
+
+	// X wins on the top row
+	for _, move := range []int{0, 3, 1, 4, 2} {
+		board.MakeMove(move/3, move%3)
+	}
+	if board.GetStatus() != Won || len(board.LegalMoves()) != 0 {
+		t.Fatalf("expected a won game with no legal moves, got status %v and moves %v", board.GetStatus(), board.LegalMoves())
+	}
+	if len(board.EmptyCells()) != 4 {
+		t.Errorf("expected 4 empty cells, got %v", board.EmptyCells())
+	}
+	winner, line := board.Winner()
+	if winner != X || len(line) != 3 || line[0] != 0 || line[1] != 1 || line[2] != 2 {
+		t.Errorf("Winner() = %v %v, want X [0 1 2]", winner, line)
+	}
+
+	// Undoing the winning move gives X the turn back
+	if !board.UndoMove() {
+		t.Fatal("UndoMove failed")
+	}
+	if board.GetStatus() != InProgress || board.GetCurrentPlayer() != X || board.Get(0, 2) != Empty {
+		t.Errorf("after undo: status %v, player %v, cell %v", board.GetStatus(), board.GetCurrentPlayer(), board.Get(0, 2))
+	}
+	if board.LastMove() != 4 || len(board.LegalMoves()) != 5 {
+		t.Errorf("after undo: last move %d, legal moves %v", board.LastMove(), board.LegalMoves())
+	}
+	if winner, line := board.Winner(); winner != Empty || line != nil {
+		t.Errorf("Winner() after undo = %v %v, want no winner", winner, line)
+	}
+
+	// The clone keeps the history but does not share it
+	clone := board.Clone()
+	clone.UndoMove()
+	if board.LastMove() != 4 || clone.LastMove() != 1 {
+		t.Errorf("clone shares history: board %v, clone %v", board.History(), clone.History())
+	}
+}
+
+func TestSymmetry(t *testing.T) {
+	// X in the top-left corner, O on the top edge
+	board := NewBoard()
+	board.MakeMove(0, 0)
+	board.MakeMove(0, 1)
+
+	tests := []struct {
+		symmetry Symmetry
+		expected string
+	}{
+		{Identity, "XO.\n...\n...\n"},
+		{Rotate90, "..X\n..O\n...\n"},
+		{Rotate180, "...\n...\n.OX\n"},
+		{Rotate270, "...\nO..\nX..\n"},
+		{ReflectHorizontal, ".OX\n...\n...\n"},
+		{ReflectVertical, "...\n...\nXO.\n"},
+		{ReflectDiagonal, "X..\nO..\n...\n"},
+		{ReflectAntiDiagonal, "...\n..O\n..X\n"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.symmetry.String(), func(t *testing.T) {
+			transformed := board.Transform(tt.symmetry)
+			if transformed.String() != tt.expected {
+				t.Errorf("Expected:\n%s\nGot:\n%s", tt.expected, transformed.String())
+			}
+			if transformed.LastMove() != tt.symmetry.TransformIndex(1) {
+				t.Errorf("history not remapped: %v", transformed.History())
+			}
+			if back := transformed.Transform(tt.symmetry.Inverse()); back.String() != board.String() {
+				t.Errorf("inverse did not restore the board:\n%s", back.String())
+			}
+
+			// Every variant has the same canonical form
+			canonical, s := transformed.Canonical()
+			expected, _ := board.Canonical()
+			if canonical.String() != expected.String() {
+				t.Errorf("canonical form differs:\n%s\nvs\n%s", canonical.String(), expected.String())
+			}
+			if transformed.Transform(s).String() != canonical.String() {
+				t.Errorf("symmetry %v does not map the board to its canonical form", s)
+			}
+		})
+	}
+}
+
+func TestHashAndKey(t *testing.T) {
+	// The same position reached in a different move order has the same hash and key
+	a := NewBoard()
+	for _, move := range []int{0, 4, 8} {
+		a.MakeMove(move/3, move%3)
+	}
+	b := NewBoard()
+	for _, move := range []int{8, 4, 0} {
+		b.MakeMove(move/3, move%3)
+	}
+	if a.Hash() != b.Hash() || a.Key() != b.Key() {
+		t.Errorf("transposed positions differ: hash %x vs %x, key %d vs %d", a.Hash(), b.Hash(), a.Key(), b.Key())
+	}
+	if a.Hash() != a.computeHash() {
+		t.Errorf("incremental hash %x does not match %x", a.Hash(), a.computeHash())
+	}
+
+	// The player to move is part of the hash but not the key
+	switched := a.Clone()
+	switched.SwitchPlayer()
+	if switched.Hash() == a.Hash() || switched.Key() != a.Key() {
+		t.Error("side to move should change the hash but not the key")
+	}
+
+	// Undoing every move returns to the empty board's hash
+	empty := NewBoard()
+	for a.UndoMove() {
+	}
+	if a.Hash() != empty.Hash() || a.Key() != 0 {
+		t.Errorf("after undo: hash %x, key %d; want %x, 0", a.Hash(), a.Key(), empty.Hash())
+	}
+
+	// Keys are exact: X in cell 8 is 1, O in cell 0 is 2*3^8
+	board := NewBoard()
+	board.Set(2, 2, X)
+	board.Set(0, 0, O)
+	if board.Key() != 1+2*6561 {
+		t.Errorf("Key() = %d, want %d", board.Key(), 1+2*6561)
+	}
+
+	// Hashes survive cloning, transforms and JSON
+	data, _ := json.Marshal(b)
+	decoded := &Board{}
+	if err := json.Unmarshal(data, decoded); err != nil {
+		t.Fatalf("Unmarshal returned error: %v", err)
+	}
+	if decoded.Hash() != b.Hash() || b.Clone().Hash() != b.Hash() {
+		t.Error("hash changed after clone or JSON round trip")
+	}
+	rotated := b.Transform(Rotate90)
+	if rotated.Hash() != rotated.computeHash() {
+		t.Error("transformed board has a stale hash")
+	}
+}
+
+func TestMNKBoard(t *testing.T) {
+	if _, err := NewMNKBoard(3, 3, 4); err == nil {
+		t.Error("expected error for a run longer than the board")
+	}
+
+	tests := []struct {
+		name          string
+		width, height int
+		k             int
+		moves         [][2]int // (row, col)
+		status        GameStatus
+		winner        Cell
+		line          []int
+	}{
+		{
+			name: "4x4 three in a row horizontally", width: 4, height: 4, k: 3,
+			moves:  [][2]int{{1, 1}, {0, 0}, {1, 3}, {0, 1}, {1, 2}},
+			status: Won, winner: X, line: []int{5, 6, 7},
+		},
+		{
+			name: "4x4 anti-diagonal through the middle of the run", width: 4, height: 4, k: 3,
+			moves:  [][2]int{{0, 0}, {0, 3}, {0, 1}, {2, 1}, {3, 3}, {1, 2}},
+			status: Won, winner: O, line: []int{3, 6, 9},
+		},
+		{
+			name: "5x3 vertical needs all rows", width: 5, height: 3, k: 3,
+			moves:  [][2]int{{0, 4}, {0, 0}, {1, 4}, {1, 0}, {2, 4}},
+			status: Won, winner: X, line: []int{4, 9, 14},
+		},
+		{
+			name: "15x15 five in a row diagonally", width: 15, height: 15, k: 5,
+			moves:  [][2]int{{3, 3}, {0, 0}, {4, 4}, {0, 1}, {6, 6}, {0, 2}, {7, 7}, {0, 3}, {5, 5}},
+			status: Won, winner: X, line: []int{48, 64, 80, 96, 112},
+		},
+		{
+			name: "2x2 two in a row is not a win before it happens", width: 2, height: 2, k: 2,
+			moves:  [][2]int{{0, 0}},
+			status: InProgress, winner: Empty,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			board, err := NewMNKBoard(tt.width, tt.height, tt.k)
+			if err != nil {
+				t.Fatalf("NewMNKBoard returned error: %v", err)
+			}
+			var result MoveResult
+			for _, move := range tt.moves {
+				if result, err = board.MakeMove(move[0], move[1]); err != nil {
+					t.Fatalf("move %v rejected: %v", move, err)
+				}
+			}
+			if result.Status != tt.status || result.Winner != tt.winner {
+				t.Errorf("expected %v/%v, got %v/%v", tt.status, tt.winner, result.Status, result.Winner)
+			}
+			if len(result.Line) != len(tt.line) {
+				t.Fatalf("expected line %v, got %v", tt.line, result.Line)
+			}
+			for i := range tt.line {
+				if result.Line[i] != tt.line[i] {
+					t.Errorf("expected line %v, got %v", tt.line, result.Line)
+					break
+				}
+			}
+		})
+	}
+
+	// A full board without a run is a draw, and undo reopens it
+	board, _ := NewMNKBoard(3, 1, 3)
+	for _, move := range []int{0, 1, 2} {
+		board.MakeMove(board.RowCol(move))
+	}
+	if board.GetStatus() != Draw || len(board.LegalMoves()) != 0 {
+		t.Errorf("expected a draw, got %v", board.GetStatus())
+	}
+	clone := board.Clone()
+	if !board.UndoMove() || board.GetStatus() != InProgress || board.GetCurrentPlayer() != X || board.LastMove() != 1 {
+		t.Errorf("undo failed: status %v, player %v, history %v", board.GetStatus(), board.GetCurrentPlayer(), board.History())
+	}
+	if clone.GetStatus() != Draw || clone.String() != "XOX\n" {
+		t.Errorf("clone changed with the original:\n%s", clone.String())
+	}
+	var occupied *CellOccupiedError
+	if _, err := board.MakeMove(0, 0); !errors.As(err, &occupied) {
+		t.Errorf("expected CellOccupiedError, got %v", err)
+	}
+}
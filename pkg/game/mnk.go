@@ -0,0 +1,272 @@
+package game
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/ZachBeta/go_neural_network_learning/internal/utils"
+)
+
+// Grid is implemented by rectangular boards with cells indexed row by row
+// Cell index i is at row i/Width() and column i%Width().
+type Grid interface {
+	Width() int
+	Height() int
+	Get(row, col int) Cell
+	GetCurrentPlayer() Cell
+	GetStatus() GameStatus
+	LegalMoves() []int
+}
+
+// Width returns the number of columns (always 3)
+func (b *Board) Width() int {
+	return 3
+}
+
+// Height returns the number of rows (always 3)
+func (b *Board) Height() int {
+	return 3
+}
+
+// directions are the four line directions as (row, col) steps:
+// horizontal, vertical, diagonal and anti-diagonal
+var directions = [4][2]int{{0, 1}, {1, 0}, {1, 1}, {1, -1}}
+
+// MNKBoard is an m,n,k-game board: width x height cells where the first player
+// to get k marks in a row, column or diagonal wins
+// Tic-tac-toe is the 3,3,3-game, Gomoku is the 15,15,5-game.
+type MNKBoard struct {
+	width, height, k int
+	cells            []Cell
+	currentPlayer    Cell
+	status           GameStatus
+	// history holds the cell index of every move, oldest first
+	history []int
+	// winner and line are set when a move completes a run of k
+	winner Cell
+	line   []int
+}
+
+// NewMNKBoard creates an empty width x height board where k in a row wins
+func NewMNKBoard(width, height, k int) (*MNKBoard, error) {
+	if width < 1 || height < 1 {
+		return nil, fmt.Errorf("invalid board size %dx%d", width, height)
+	}
+	if k < 1 || (k > width && k > height) {
+		return nil, fmt.Errorf("run length %d does not fit on a %dx%d board", k, width, height)
+	}
+	utils.Info("Creating new %dx%d board with %d in a row", width, height, k)
+	return &MNKBoard{
+		width:         width,
+		height:        height,
+		k:             k,
+		cells:         make([]Cell, width*height),
+		currentPlayer: X,
+		status:        InProgress,
+	}, nil
+}
+
+// Width returns the number of columns
+func (b *MNKBoard) Width() int {
+	return b.width
+}
+
+// Height returns the number of rows
+func (b *MNKBoard) Height() int {
+	return b.height
+}
+
+// K returns the number of marks in a row needed to win
+func (b *MNKBoard) K() int {
+	return b.k
+}
+
+// Size returns the number of cells
+func (b *MNKBoard) Size() int {
+	return len(b.cells)
+}
+
+// Index converts row and column coordinates to a cell index
+func (b *MNKBoard) Index(row, col int) int {
+	return row*b.width + col
+}
+
+// RowCol converts a cell index to row and column coordinates
+func (b *MNKBoard) RowCol(index int) (row, col int) {
+	return index / b.width, index % b.width
+}
+
+// isValidPosition checks if the given position is on the board
+func (b *MNKBoard) isValidPosition(row, col int) bool {
+	return row >= 0 && row < b.height && col >= 0 && col < b.width
+}
+
+// Get returns the cell value at the given position
+func (b *MNKBoard) Get(row, col int) Cell {
+	if !b.isValidPosition(row, col) {
+		return Empty
+	}
+	return b.cells[b.Index(row, col)]
+}
+
+// GetCurrentPlayer returns the current player
+func (b *MNKBoard) GetCurrentPlayer() Cell {
+	return b.currentPlayer
+}
+
+// GetStatus returns the current game status
+func (b *MNKBoard) GetStatus() GameStatus {
+	return b.status
+}
+
+// MakeMove makes a move for the current player at the specified position
+// Only the lines through the new mark are checked for a win, so a move costs O(k).
+// A rejected move leaves the board unchanged and returns a *GameOverError,
+// *InvalidPositionError or *CellOccupiedError.
+func (b *MNKBoard) MakeMove(row, col int) (MoveResult, error) {
+	if b.status != InProgress {
+		return MoveResult{}, &GameOverError{Status: b.status}
+	}
+	if !b.isValidPosition(row, col) {
+		return MoveResult{}, &InvalidPositionError{Row: row, Col: col}
+	}
+	index := b.Index(row, col)
+	if occupant := b.cells[index]; occupant != Empty {
+		return MoveResult{}, &CellOccupiedError{Row: row, Col: col, Occupant: occupant}
+	}
+
+	player := b.currentPlayer
+	utils.Debug("Player %v making move at position (%d,%d)", player, row, col)
+	b.cells[index] = player
+	b.history = append(b.history, index)
+	b.currentPlayer = opponent(player)
+
+	if line := b.runThrough(row, col); line != nil {
+		b.winner, b.line = player, line
+		b.status = Won
+	} else if len(b.history) == len(b.cells) {
+		b.status = Draw
+	}
+
+	result := MoveResult{
+		Player: player,
+		Index:  index,
+		Status: b.status,
+	}
+	result.Winner, result.Line = b.Winner()
+	return result, nil
+}
+
+// runThrough returns the cell indices of a run of at least k marks through (row, col),
+// or nil if there is none
+func (b *MNKBoard) runThrough(row, col int) []int {
+	player := b.cells[b.Index(row, col)]
+	for _, dir := range directions {
+		line := []int{b.Index(row, col)}
+		// Walk backwards, then forwards, from the new mark
+		for _, sign := range []int{-1, 1} {
+			r, c := row+sign*dir[0], col+sign*dir[1]
+			for b.isValidPosition(r, c) && b.cells[b.Index(r, c)] == player {
+				if sign < 0 {
+					line = append([]int{b.Index(r, c)}, line...)
+				} else {
+					line = append(line, b.Index(r, c))
+				}
+				r, c = r+sign*dir[0], c+sign*dir[1]
+			}
+		}
+		if len(line) >= b.k {
+			return line
+		}
+	}
+	return nil
+}
+
+// UndoMove takes back the last move
+// It returns false if there is no move to undo.
+func (b *MNKBoard) UndoMove() bool {
+	if len(b.history) == 0 {
+		return false
+	}
+	last := b.history[len(b.history)-1]
+	b.history = b.history[:len(b.history)-1]
+
+	b.currentPlayer = b.cells[last]
+	b.cells[last] = Empty
+	b.status = InProgress
+	b.winner, b.line = Empty, nil
+	return true
+}
+
+// LastMove returns the cell index of the last move, or -1 if no move has been made
+func (b *MNKBoard) LastMove() int {
+	if len(b.history) == 0 {
+		return -1
+	}
+	return b.history[len(b.history)-1]
+}
+
+// History returns the cell indices of all moves made so far, oldest first
+func (b *MNKBoard) History() []int {
+	return append([]int(nil), b.history...)
+}
+
+// EmptyCells returns the indices of all empty cells, whether or not the game is over
+func (b *MNKBoard) EmptyCells() []int {
+	cells := make([]int, 0, len(b.cells)-len(b.history))
+	for i, cell := range b.cells {
+		if cell == Empty {
+			cells = append(cells, i)
+		}
+	}
+	return cells
+}
+
+// LegalMoves returns the indices of the cells the current player may play
+// There are no legal moves once the game is over.
+func (b *MNKBoard) LegalMoves() []int {
+	if b.status != InProgress {
+		return []int{}
+	}
+	return b.EmptyCells()
+}
+
+// Winner returns the player who completed a run of k and the indices of that run
+// It returns Empty and nil if nobody has won.
+func (b *MNKBoard) Winner() (Cell, []int) {
+	if b.winner == Empty {
+		return Empty, nil
+	}
+	return b.winner, append([]int(nil), b.line...)
+}
+
+// Clone creates a deep copy of the board
+func (b *MNKBoard) Clone() *MNKBoard {
+	clone := *b
+	clone.cells = append([]Cell(nil), b.cells...)
+	clone.history = append([]int(nil), b.history...)
+	if b.line != nil {
+		clone.line = append([]int(nil), b.line...)
+	}
+	return &clone
+}
+
+// String returns a string representation of the board
+func (b *MNKBoard) String() string {
+	var result strings.Builder
+	for i, cell := range b.cells {
+		result.WriteByte(cellToByte(cell))
+		if (i+1)%b.width == 0 {
+			result.WriteByte('\n')
+		}
+	}
+	return result.String()
+}
+
+// opponent returns the other player
+func opponent(player Cell) Cell {
+	if player == X {
+		return O
+	}
+	return X
+}
@@ -0,0 +1,167 @@
+package game
+
+import "fmt"
+
+// State is a position in a two-player, turn-based game with a fixed set of numbered moves
+// Board, MNKBoard and the boards of other game packages implement it, so self-play,
+// evaluation and the command line tools can work with any of them.
+type State interface {
+	// GetCurrentPlayer returns the player to move
+	GetCurrentPlayer() Cell
+
+	// GetStatus returns whether the game is in progress, won or drawn
+	GetStatus() GameStatus
+
+	// LegalMoves returns the moves the player to move may make, none once the game is over
+	LegalMoves() []int
+
+	// Apply makes a move for the player to move and updates the game status
+	Apply(move int) (MoveResult, error)
+
+	// Winner returns the winning player and the cells of the winning line,
+	// or Empty and nil if nobody has won
+	Winner() (Cell, []int)
+
+	// Encode returns the position as a neural network input vector
+	Encode() []float64
+
+	// ActionSize returns the number of distinct moves, which is the size of a network's output
+	ActionSize() int
+
+	// CloneState returns an independent copy of the position
+	CloneState() State
+
+	// String returns a printable representation of the position
+	String() string
+}
+
+// Game describes a game and creates its starting position
+type Game interface {
+	// Name returns a short name used on the command line and in checkpoints
+	Name() string
+
+	// NewState returns the starting position
+	NewState() State
+
+	// InputSize returns the length of the vector returned by State.Encode
+	InputSize() int
+
+	// ActionSize returns the number of distinct moves
+	ActionSize() int
+}
+
+// Both boards in this package are States
+var (
+	_ State = (*Board)(nil)
+	_ State = (*MNKBoard)(nil)
+)
+
+// TicTacToe is the Game for the standard 3x3 Board
+type TicTacToe struct{}
+
+// Name returns the name of the game
+func (TicTacToe) Name() string {
+	return "tictactoe"
+}
+
+// NewState returns an empty board
+func (TicTacToe) NewState() State {
+	return NewBoard()
+}
+
+// InputSize returns the length of the board encoding
+func (TicTacToe) InputSize() int {
+	return 9
+}
+
+// ActionSize returns the number of cells
+func (TicTacToe) ActionSize() int {
+	return 9
+}
+
+// MNK is the Game for an MNKBoard of the given size and run length
+type MNK struct {
+	Width, Height, K int
+}
+
+// Name returns the name of the game, such as mnk-4x4-3
+func (g MNK) Name() string {
+	return fmt.Sprintf("mnk-%dx%d-%d", g.Width, g.Height, g.K)
+}
+
+// NewState returns an empty board
+// It panics if the dimensions are invalid; NewMNKBoard reports them as an error
+func (g MNK) NewState() State {
+	board, err := NewMNKBoard(g.Width, g.Height, g.K)
+	if err != nil {
+		panic(err)
+	}
+	return board
+}
+
+// InputSize returns the length of the board encoding
+func (g MNK) InputSize() int {
+	return g.Width * g.Height
+}
+
+// ActionSize returns the number of cells
+func (g MNK) ActionSize() int {
+	return g.Width * g.Height
+}
+
+// Apply plays the move with the given cell index (0-8)
+func (b *Board) Apply(move int) (MoveResult, error) {
+	return b.MakeMove(move/3, move%3)
+}
+
+// Encode returns the board as a network input: X = 1.0, O = -1.0, Empty = 0.0
+func (b *Board) Encode() []float64 {
+	return encodeCells(b.cells[:])
+}
+
+// ActionSize returns the number of cells
+func (b *Board) ActionSize() int {
+	return 9
+}
+
+// CloneState returns a copy of the board as a State
+func (b *Board) CloneState() State {
+	return b.Clone()
+}
+
+// Apply plays the move with the given cell index
+func (b *MNKBoard) Apply(move int) (MoveResult, error) {
+	if move < 0 || move >= len(b.cells) {
+		return MoveResult{}, &InvalidPositionError{Row: move / b.width, Col: move % b.width}
+	}
+	return b.MakeMove(b.RowCol(move))
+}
+
+// Encode returns the board as a network input: X = 1.0, O = -1.0, Empty = 0.0
+func (b *MNKBoard) Encode() []float64 {
+	return encodeCells(b.cells)
+}
+
+// ActionSize returns the number of cells
+func (b *MNKBoard) ActionSize() int {
+	return len(b.cells)
+}
+
+// CloneState returns a copy of the board as a State
+func (b *MNKBoard) CloneState() State {
+	return b.Clone()
+}
+
+// encodeCells converts cells to network inputs: X = 1.0, O = -1.0, Empty = 0.0
+func encodeCells(cells []Cell) []float64 {
+	input := make([]float64, len(cells))
+	for i, cell := range cells {
+		switch cell {
+		case X:
+			input[i] = 1.0
+		case O:
+			input[i] = -1.0
+		}
+	}
+	return input
+}
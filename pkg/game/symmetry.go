@@ -0,0 +1,124 @@
+package game
+
+// Symmetry is one of the 8 symmetries of the square board (the dihedral group D4)
+// A symmetry maps a position to an equivalent one: the same moves win, draw or lose.
+type Symmetry int
+
+const (
+	Identity Symmetry = iota
+	Rotate90
+	Rotate180
+	Rotate270
+	ReflectHorizontal   // mirror left to right
+	ReflectVertical     // mirror top to bottom
+	ReflectDiagonal     // mirror across the top-left to bottom-right diagonal
+	ReflectAntiDiagonal // mirror across the top-right to bottom-left diagonal
+)
+
+// Symmetries lists all 8 symmetries, starting with Identity
+var Symmetries = [8]Symmetry{
+	Identity, Rotate90, Rotate180, Rotate270,
+	ReflectHorizontal, ReflectVertical, ReflectDiagonal, ReflectAntiDiagonal,
+}
+
+// String returns the name of the symmetry
+func (s Symmetry) String() string {
+	switch s {
+	case Identity:
+		return "identity"
+	case Rotate90:
+		return "rotate90"
+	case Rotate180:
+		return "rotate180"
+	case Rotate270:
+		return "rotate270"
+	case ReflectHorizontal:
+		return "reflect-horizontal"
+	case ReflectVertical:
+		return "reflect-vertical"
+	case ReflectDiagonal:
+		return "reflect-diagonal"
+	case ReflectAntiDiagonal:
+		return "reflect-anti-diagonal"
+	default:
+		return "unknown"
+	}
+}
+
+// TransformIndex returns where the cell index (0-8) ends up after applying the symmetry
+// Rotations are clockwise.
+func (s Symmetry) TransformIndex(index int) int {
+	row, col := index/3, index%3
+	switch s {
+	case Rotate90:
+		row, col = col, 2-row
+	case Rotate180:
+		row, col = 2-row, 2-col
+	case Rotate270:
+		row, col = 2-col, row
+	case ReflectHorizontal:
+		col = 2 - col
+	case ReflectVertical:
+		row = 2 - row
+	case ReflectDiagonal:
+		row, col = col, row
+	case ReflectAntiDiagonal:
+		row, col = 2-col, 2-row
+	}
+	return row*3 + col
+}
+
+// Inverse returns the symmetry that undoes s
+func (s Symmetry) Inverse() Symmetry {
+	switch s {
+	case Rotate90:
+		return Rotate270
+	case Rotate270:
+		return Rotate90
+	default:
+		// Every other symmetry is its own inverse
+		return s
+	}
+}
+
+// Transform returns a copy of the board with the symmetry applied
+// The current player, status and move history carry over, with the history remapped.
+func (b *Board) Transform(s Symmetry) *Board {
+	transformed := b.Clone()
+	for i, cell := range b.cells {
+		transformed.cells[s.TransformIndex(i)] = cell
+	}
+	for i, move := range b.history {
+		transformed.history[i] = s.TransformIndex(move)
+	}
+	transformed.hash = transformed.computeHash()
+	return transformed
+}
+
+// Canonical returns the representative of the board's symmetry class and the symmetry
+// that maps the board onto it. Equivalent boards always have the same canonical form,
+// which is the variant whose cells come first in row-major order (Empty < X < O).
+func (b *Board) Canonical() (*Board, Symmetry) {
+	best := Identity
+	bestCells := b.cells
+	for _, s := range Symmetries[1:] {
+		var cells [9]Cell
+		for i, cell := range b.cells {
+			cells[s.TransformIndex(i)] = cell
+		}
+		if lessCells(cells, bestCells) {
+			best, bestCells = s, cells
+		}
+	}
+	return b.Transform(best), best
+}
+
+// lessCells reports whether a comes before b in row-major order
+func lessCells(a, b [9]Cell) bool {
+	for i := range a {
+		if a[i] != b[i] {
+			return a[i] < b[i]
+		}
+	}
+	return false
+}
@@ -0,0 +1,68 @@
+package game
+
+// zobristSeed fixes the Zobrist keys so hashes are stable between runs
+const zobristSeed = 0x5eed0f7ac7ac70e
+
+// zobristCells holds a random key for every cell and mark; the keys for Empty are 0
+// zobristSide is mixed in when O is to move
+var (
+	zobristCells [9][3]uint64
+	zobristSide  uint64
+)
+
+func init() {
+	state := uint64(zobristSeed)
+	for i := range zobristCells {
+		zobristCells[i][X] = splitMix64(&state)
+		zobristCells[i][O] = splitMix64(&state)
+	}
+	zobristSide = splitMix64(&state)
+}
+
+// splitMix64 advances the state and returns the next pseudo-random number
+func splitMix64(state *uint64) uint64 {
+	*state += 0x9e3779b97f4a7c15
+	z := *state
+	z = (z ^ (z >> 30)) * 0xbf58476d1ce4e5b9
+	z = (z ^ (z >> 27)) * 0x94d049bb133111eb
+	return z ^ (z >> 31)
+}
+
+// Hash returns the Zobrist hash of the position, including the player to move
+// It is updated incrementally as moves are made and undone, so it costs nothing to read.
+// Equal positions always have equal hashes; different positions collide only rarely,
+// so use Key when an exact match is required.
+func (b *Board) Hash() uint64 {
+	return b.hash
+}
+
+// Key returns the cells encoded as a base-3 number (Empty=0, X=1, O=2), cell 0 first
+// Every arrangement of cells has its own key, between 0 and 3^9-1 = 19682.
+// The player to move is not part of the key.
+func (b *Board) Key() int {
+	key := 0
+	for _, cell := range b.cells {
+		key = key*3 + int(cell)
+	}
+	return key
+}
+
+// computeHash calculates the Zobrist hash from scratch
+func (b *Board) computeHash() uint64 {
+	var hash uint64
+	for i, cell := range b.cells {
+		hash ^= zobristCell(i, cell)
+	}
+	if b.currentPlayer == O {
+		hash ^= zobristSide
+	}
+	return hash
+}
+
+// zobristCell returns the key for a mark at cell index i
+func zobristCell(i int, cell Cell) uint64 {
+	if cell != X && cell != O {
+		return 0
+	}
+	return zobristCells[i][cell]
+}
@@ -0,0 +1,52 @@
+package games
+
+import (
+	"fmt"
+	"sort"
+
+	"github.com/ZachBeta/go_neural_network_learning/pkg/connectfour"
+	"github.com/ZachBeta/go_neural_network_learning/pkg/game"
+	"github.com/ZachBeta/go_neural_network_learning/pkg/ultimate"
+)
+
+// gameConstructors maps game names to constructors
+var gameConstructors = map[string]func() game.Game{
+	"tictactoe":   func() game.Game { return game.TicTacToe{} },
+	"connectfour": func() game.Game { return connectfour.Game{} },
+	"ultimate":    func() game.Game { return ultimate.Game{} },
+}
+
+// New creates a game from its Name()
+// Besides the names returned by Names, any m,n,k-game can be created as
+// mnk-<width>x<height>-<k>, for example mnk-4x4-3 or mnk-15x15-5 (Gomoku)
+func New(name string) (game.Game, error) {
+	if constructor, ok := gameConstructors[name]; ok {
+		return constructor(), nil
+	}
+
+	var mnk game.MNK
+	if _, err := fmt.Sscanf(name, "mnk-%dx%d-%d", &mnk.Width, &mnk.Height, &mnk.K); err == nil && mnk.Name() == name {
+		if _, err := game.NewMNKBoard(mnk.Width, mnk.Height, mnk.K); err != nil {
+			return nil, err
+		}
+		return mnk, nil
+	}
+
+	return nil, fmt.Errorf("unknown game %q (available: %v or mnk-<width>x<height>-<k>)", name, Names())
+}
+
+// Register makes a game available to New
+// Registering a name that already exists replaces the previous constructor
+func Register(name string, constructor func() game.Game) {
+	gameConstructors[name] = constructor
+}
+
+// Names returns the names of the registered games in sorted order
+func Names() []string {
+	names := make([]string, 0, len(gameConstructors))
+	for name := range gameConstructors {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
@@ -0,0 +1,64 @@
+package games
+
+import (
+	"testing"
+
+	"github.com/ZachBeta/go_neural_network_learning/internal/utils"
+	"github.com/ZachBeta/go_neural_network_learning/pkg/game"
+)
+
+func TestNew(t *testing.T) {
+	utils.SetLogLevel(utils.ERROR)
+
+	tests := []struct {
+		name       string
+		inputSize  int
+		actionSize int
+		wantErr    bool
+	}{
+		{"tictactoe", 9, 9, false},
+		{"connectfour", 84, 7, false},
+		{"ultimate", 243, 81, false},
+		{"mnk-4x4-3", 16, 16, false},
+		{"mnk-15x15-5", 225, 225, false},
+		{"mnk-3x3-4", 0, 0, true},
+		{"chess", 0, 0, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			g, err := New(tt.name)
+			if tt.wantErr {
+				if err == nil {
+					t.Errorf("expected an error for %q", tt.name)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("New(%q) returned error: %v", tt.name, err)
+			}
+			if g.Name() != tt.name {
+				t.Errorf("Name() = %q, want %q", g.Name(), tt.name)
+			}
+
+			state := g.NewState()
+			if len(state.Encode()) != tt.inputSize || g.InputSize() != tt.inputSize {
+				t.Errorf("input size: encoded %d, game %d; want %d", len(state.Encode()), g.InputSize(), tt.inputSize)
+			}
+			if state.ActionSize() != tt.actionSize || g.ActionSize() != tt.actionSize {
+				t.Errorf("action size: state %d, game %d; want %d", state.ActionSize(), g.ActionSize(), tt.actionSize)
+			}
+
+			// Play the first legal move until the game ends; a clone is unaffected
+			start := state.CloneState()
+			for state.GetStatus() == game.InProgress {
+				if _, err := state.Apply(state.LegalMoves()[0]); err != nil {
+					t.Fatalf("legal move rejected: %v", err)
+				}
+			}
+			if len(state.LegalMoves()) != 0 || len(start.LegalMoves()) != tt.actionSize {
+				t.Errorf("finished game has %d legal moves, clone has %d", len(state.LegalMoves()), len(start.LegalMoves()))
+			}
+		})
+	}
+}
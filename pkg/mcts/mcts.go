@@ -0,0 +1,296 @@
+package mcts
+
+import (
+	"math"
+	"math/rand/v2"
+
+	"github.com/ZachBeta/go_neural_network_learning/pkg/game"
+	"github.com/ZachBeta/go_neural_network_learning/pkg/neural"
+)
+
+// Evaluator gives the prior move probabilities and the value of a position
+// The value is from the point of view of the player to move: 1 is a win, -1 a loss.
+type Evaluator interface {
+	Evaluate(state game.State) (priors []float64, value float64)
+}
+
+// NetworkEvaluator evaluates positions with the two heads of a policy/value network
+type NetworkEvaluator struct {
+	Network *neural.PolicyValueNetwork
+}
+
+// Evaluate returns the network's policy over the legal moves and its value estimate
+func (e NetworkEvaluator) Evaluate(state game.State) ([]float64, float64) {
+	return neural.PredictPolicyValue(e.Network, state)
+}
+
+// RolloutEvaluator takes its priors from a policy network and values a position
+// by playing it out with random moves
+// A nil Policy gives every legal move the same prior, which is plain MCTS.
+type RolloutEvaluator struct {
+	Policy *neural.Network
+	RNG    *rand.Rand
+}
+
+// Evaluate returns the priors and the result of one random playout
+func (e RolloutEvaluator) Evaluate(state game.State) ([]float64, float64) {
+	mask := neural.StateMoveMask(state)
+	var priors []float64
+	if e.Policy != nil {
+		priors = neural.PredictStateProbabilities(e.Policy, state)
+	} else {
+		priors = neural.MaskProbabilities(make([]float64, len(mask)), mask)
+	}
+
+	player := state.GetCurrentPlayer()
+	playout := state.CloneState()
+	for playout.GetStatus() == game.InProgress {
+		moves := playout.LegalMoves()
+		if _, err := playout.Apply(moves[e.RNG.IntN(len(moves))]); err != nil {
+			break
+		}
+	}
+	winner, _ := playout.Winner()
+	switch winner {
+	case game.Empty:
+		return priors, 0
+	case player:
+		return priors, 1
+	default:
+		return priors, -1
+	}
+}
+
+// Config holds the search parameters
+type Config struct {
+	// Simulations is the number of simulations run for each search
+	Simulations int
+
+	// CPuct weighs the prior and visit counts against the value in the PUCT formula
+	CPuct float64
+
+	// DirichletAlpha is the concentration of the noise added to the root priors
+	DirichletAlpha float64
+
+	// NoiseFraction is how much of the root priors is replaced by Dirichlet noise (0 disables it)
+	// Self-play uses noise so every game explores; evaluation should turn it off.
+	NoiseFraction float64
+}
+
+// DefaultConfig returns search parameters suited to tic-tac-toe sized games
+// AlphaZero used an alpha of about 10 divided by the typical number of legal moves.
+func DefaultConfig() Config {
+	return Config{
+		Simulations:    100,
+		CPuct:          1.5,
+		DirichletAlpha: 0.3,
+		NoiseFraction:  0.25,
+	}
+}
+
+// Search runs Monte Carlo Tree Search with PUCT move selection
+type Search struct {
+	config    Config
+	evaluator Evaluator
+	rng       *rand.Rand
+}
+
+// New creates a search; rng is used for the root noise
+func New(evaluator Evaluator, config Config, rng *rand.Rand) *Search {
+	return &Search{config: config, evaluator: evaluator, rng: rng}
+}
+
+// Result is the outcome of a search from one position
+type Result struct {
+	// Visits counts the simulations that went through each move
+	Visits []int
+
+	// Value is the average value of the simulations for the player to move
+	Value float64
+}
+
+// Policy returns the visit counts as move probabilities, reshaped by the temperature
+// A temperature of 1 is proportional to the visit counts and 0 picks the most visited move.
+// It is the policy target for training a policy/value network.
+func (r Result) Policy(temperature float64) []float64 {
+	total := 0
+	for _, visits := range r.Visits {
+		total += visits
+	}
+	probabilities := make([]float64, len(r.Visits))
+	if total == 0 {
+		return probabilities
+	}
+	for i, visits := range r.Visits {
+		probabilities[i] = float64(visits) / float64(total)
+	}
+	return neural.ApplyTemperature(probabilities, temperature)
+}
+
+// BestMove returns the most visited move, or -1 if no move was searched
+func (r Result) BestMove() int {
+	best := -1
+	for move, visits := range r.Visits {
+		if visits > 0 && (best == -1 || visits > r.Visits[best]) {
+			best = move
+		}
+	}
+	return best
+}
+
+// node is a position in the search tree
+type node struct {
+	// children is nil until the node is expanded, then holds one entry per legal move
+	children []*child
+	visits   int
+}
+
+// child is an edge from a node to the position after one move
+// valueSum is from the point of view of the player making the move
+type child struct {
+	move     int
+	prior    float64
+	visits   int
+	valueSum float64
+	node     *node
+}
+
+// Run searches from the given position, which is left unchanged
+func (s *Search) Run(state game.State) Result {
+	result := Result{Visits: make([]int, state.ActionSize())}
+	if state.GetStatus() != game.InProgress {
+		return result
+	}
+
+	root := &node{}
+	s.expand(root, state)
+	if s.config.NoiseFraction > 0 {
+		s.addNoise(root)
+	}
+
+	for i := 0; i < s.config.Simulations; i++ {
+		s.simulate(root, state.CloneState())
+	}
+
+	total := 0.0
+	for _, c := range root.children {
+		result.Visits[c.move] = c.visits
+		total += c.valueSum
+	}
+	if root.visits > 0 {
+		result.Value = total / float64(root.visits)
+	}
+	return result
+}
+
+// simulate walks down the tree from n, expands a leaf and backs up its value
+// It returns the value of the position for the player to move in it
+func (s *Search) simulate(n *node, state game.State) float64 {
+	if state.GetStatus() != game.InProgress {
+		return terminalValue(state)
+	}
+	if n.children == nil {
+		return s.expand(n, state)
+	}
+
+	c := s.selectChild(n)
+	if c.node == nil {
+		c.node = &node{}
+	}
+	var value float64
+	if _, err := state.Apply(c.move); err != nil {
+		// The move came from LegalMoves, so this only happens if the state is broken;
+		// count it as a loss so the search avoids it
+		value = -1
+	} else {
+		value = -s.simulate(c.node, state)
+	}
+
+	c.visits++
+	c.valueSum += value
+	n.visits++
+	return value
+}
+
+// expand evaluates a leaf, creates its children and returns its value
+func (s *Search) expand(n *node, state game.State) float64 {
+	priors, value := s.evaluator.Evaluate(state)
+	moves := state.LegalMoves()
+	n.children = make([]*child, len(moves))
+	for i, move := range moves {
+		n.children[i] = &child{move: move, prior: priors[move]}
+	}
+	return value
+}
+
+// selectChild picks the child with the highest PUCT score
+// Q + cpuct * P * sqrt(N) / (1 + n), where unvisited children have Q = 0.
+// N counts at least 1 so the first descent from a node follows the priors
+// instead of a score of 0 for every child.
+func (s *Search) selectChild(n *node) *child {
+	sqrtVisits := math.Sqrt(math.Max(1, float64(n.visits)))
+	var best *child
+	bestScore := math.Inf(-1)
+	for _, c := range n.children {
+		q := 0.0
+		if c.visits > 0 {
+			q = c.valueSum / float64(c.visits)
+		}
+		score := q + s.config.CPuct*c.prior*sqrtVisits/float64(1+c.visits)
+		if score > bestScore {
+			best, bestScore = c, score
+		}
+	}
+	return best
+}
+
+// addNoise mixes Dirichlet noise into the priors of the root's children
+func (s *Search) addNoise(root *node) {
+	noise := make([]float64, len(root.children))
+	sum := 0.0
+	for i := range noise {
+		noise[i] = gamma(s.config.DirichletAlpha, s.rng)
+		sum += noise[i]
+	}
+	if sum == 0 {
+		return
+	}
+	for i, c := range root.children {
+		c.prior = (1-s.config.NoiseFraction)*c.prior + s.config.NoiseFraction*noise[i]/sum
+	}
+}
+
+// terminalValue returns the value of a finished game for the player to move
+// The player to move did not make the last move, so a win is always a loss for them.
+func terminalValue(state game.State) float64 {
+	if winner, _ := state.Winner(); winner != game.Empty {
+		if winner == state.GetCurrentPlayer() {
+			return 1
+		}
+		return -1
+	}
+	return 0
+}
+
+// gamma samples from a Gamma(alpha, 1) distribution with the Marsaglia-Tsang method
+// Normalized gamma samples form a Dirichlet sample.
+func gamma(alpha float64, rng *rand.Rand) float64 {
+	if alpha < 1 {
+		// Boost to alpha+1 and scale back down
+		return gamma(alpha+1, rng) * math.Pow(rng.Float64(), 1/alpha)
+	}
+	d := alpha - 1.0/3.0
+	c := 1 / math.Sqrt(9*d)
+	for {
+		x := rng.NormFloat64()
+		v := 1 + c*x
+		if v <= 0 {
+			continue
+		}
+		v = v * v * v
+		u := rng.Float64()
+		if math.Log(u) < 0.5*x*x+d-d*v+d*math.Log(v) {
+			return d * v
+		}
+	}
+}
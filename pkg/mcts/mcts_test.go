@@ -0,0 +1,135 @@
+package mcts
+
+import (
+	"math"
+	"math/rand/v2"
+	"testing"
+
+	"github.com/ZachBeta/go_neural_network_learning/internal/gametest"
+	"github.com/ZachBeta/go_neural_network_learning/internal/utils"
+	"github.com/ZachBeta/go_neural_network_learning/pkg/game"
+	"github.com/ZachBeta/go_neural_network_learning/pkg/neural"
+)
+
+func TestSearchFindsTactics(t *testing.T) {
+	utils.SetLogLevel(utils.ERROR)
+
+	tests := []struct {
+		name  string
+		moves []int
+		want  int
+	}{
+		// X: 0, 1; O: 3, 4 -> X wins at 2
+		{"win", []int{0, 3, 1, 4}, 2},
+		// X: 0, 8; O: 4, 5 -> O threatens 3, X must block
+		{"block", []int{0, 4, 8, 5}, 3},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rng := rand.New(rand.NewPCG(1, 2))
+			config := DefaultConfig()
+			config.Simulations = 400
+			config.NoiseFraction = 0
+			search := New(RolloutEvaluator{RNG: rng}, config, rng)
+
+			board := gametest.Board(t, tt.moves...)
+			result := search.Run(board)
+			if move := result.BestMove(); move != tt.want {
+				t.Errorf("BestMove() = %d, want %d (visits %v)", move, tt.want, result.Visits)
+			}
+			if board.LastMove() != tt.moves[len(tt.moves)-1] {
+				t.Errorf("search changed the board")
+			}
+
+			// Visits only go to legal moves and add up to the simulations
+			total := 0
+			for move, visits := range result.Visits {
+				if visits > 0 && board.Get(move/3, move%3) != game.Empty {
+					t.Errorf("occupied cell %d was visited", move)
+				}
+				total += visits
+			}
+			if total != config.Simulations {
+				t.Errorf("visits add up to %d, want %d", total, config.Simulations)
+			}
+		})
+	}
+}
+
+// priorEvaluator gives fixed priors and a value of 0 to every position
+type priorEvaluator struct {
+	priors []float64
+}
+
+func (e priorEvaluator) Evaluate(state game.State) ([]float64, float64) {
+	return neural.MaskProbabilities(append([]float64(nil), e.priors...), neural.StateMoveMask(state)), 0
+}
+
+func TestFirstSimulationFollowsPrior(t *testing.T) {
+	utils.SetLogLevel(utils.ERROR)
+
+	priors := []float64{0.00125, 0.00125, 0.00125, 0.00125, 0.00125, 0.00125, 0.00125, 0.00125, 0.99}
+	for _, simulations := range []int{1, 2} {
+		rng := rand.New(rand.NewPCG(1, 2))
+		config := DefaultConfig()
+		config.Simulations = simulations
+		config.NoiseFraction = 0
+		result := New(priorEvaluator{priors: priors}, config, rng).Run(game.NewBoard())
+		if result.Visits[8] != simulations {
+			t.Errorf("%d simulations: visits %v, want all on move 8", simulations, result.Visits)
+		}
+	}
+}
+
+func TestPolicyAndNoise(t *testing.T) {
+	utils.SetLogLevel(utils.ERROR)
+
+	result := Result{Visits: []int{0, 10, 30, 0, 60, 0, 0, 0, 0}}
+	policy := result.Policy(1)
+	if math.Abs(policy[4]-0.6) > 1e-12 || policy[0] != 0 {
+		t.Errorf("Policy(1) = %v", policy)
+	}
+	if greedy := result.Policy(0); greedy[4] != 1 {
+		t.Errorf("Policy(0) = %v, want all mass on move 4", greedy)
+	}
+
+	// Dirichlet noise keeps the root priors a distribution
+	rng := rand.New(rand.NewPCG(3, 4))
+	search := New(RolloutEvaluator{RNG: rng}, DefaultConfig(), rng)
+	root := &node{}
+	search.expand(root, game.NewBoard())
+	search.addNoise(root)
+	sum := 0.0
+	for _, c := range root.children {
+		if c.prior <= 0 {
+			t.Errorf("move %d has prior %v", c.move, c.prior)
+		}
+		sum += c.prior
+	}
+	if math.Abs(sum-1) > 1e-9 {
+		t.Errorf("priors sum to %v", sum)
+	}
+}
+
+func TestNetworkEvaluator(t *testing.T) {
+	utils.SetLogLevel(utils.ERROR)
+	neural.SetRandomSeed(7)
+
+	network, err := neural.NewPolicyValueNetwork([]int{9, 16}, 9, nil)
+	if err != nil {
+		t.Fatalf("NewPolicyValueNetwork returned error: %v", err)
+	}
+	rng := rand.New(rand.NewPCG(5, 6))
+	config := DefaultConfig()
+	config.Simulations = 50
+	result := New(NetworkEvaluator{Network: network}, config, rng).Run(gametest.Board(t, 4))
+	if result.Visits[4] != 0 || result.BestMove() < 0 || result.Value < -1 || result.Value > 1 {
+		t.Errorf("visits %v, value %v", result.Visits, result.Value)
+	}
+
+	// A finished game has nothing to search
+	if finished := New(NetworkEvaluator{Network: network}, config, rng).Run(gametest.Board(t, 0, 3, 1, 4, 2)); finished.BestMove() != -1 {
+		t.Errorf("finished game returned move %d", finished.BestMove())
+	}
+}
@@ -1,7 +1,9 @@
 package neural
 
 import (
+	"fmt"
 	"math"
+	"sort"
 )
 
 // ActivationFunction defines the interface for activation functions
@@ -36,3 +38,247 @@ func (s *Sigmoid) Derivative(x float64) float64 {
 func (s *Sigmoid) Name() string {
 	return "sigmoid"
 }
+
+// VectorActivation is implemented by activation functions that act on a whole layer at once
+// Layers whose neurons use a VectorActivation compute every neuron's weighted sum first
+// and then activate them together, instead of activating each neuron independently
+type VectorActivation interface {
+	ActivationFunction
+
+	// ActivateVector applies the activation function to all of a layer's weighted sums
+	ActivateVector(sums []float64) []float64
+
+	// BackwardVector returns the gradient with respect to the weighted sums,
+	// given the layer's outputs and the gradient with respect to those outputs
+	// (the vector-Jacobian product of the activation)
+	BackwardVector(outputs, outputGrad []float64) []float64
+}
+
+// Tanh implements the hyperbolic tangent activation function
+// f(x) = (e^x - e^(-x)) / (e^x + e^(-x))
+type Tanh struct{}
+
+// Activate applies the tanh function to the input
+func (t *Tanh) Activate(x float64) float64 {
+	return math.Tanh(x)
+}
+
+// Derivative returns the derivative of the tanh function at the given input
+// f'(x) = 1 - f(x)^2
+func (t *Tanh) Derivative(x float64) float64 {
+	th := math.Tanh(x)
+	return 1.0 - th*th
+}
+
+// Name returns the name of the activation function
+func (t *Tanh) Name() string {
+	return "tanh"
+}
+
+// ReLU implements the rectified linear unit activation function
+// f(x) = max(0, x)
+type ReLU struct{}
+
+// Activate applies the ReLU function to the input
+func (r *ReLU) Activate(x float64) float64 {
+	if x > 0 {
+		return x
+	}
+	return 0.0
+}
+
+// Derivative returns the derivative of the ReLU function at the given input
+// f'(x) = 1 if x > 0, otherwise 0
+func (r *ReLU) Derivative(x float64) float64 {
+	if x > 0 {
+		return 1.0
+	}
+	return 0.0
+}
+
+// Name returns the name of the activation function
+func (r *ReLU) Name() string {
+	return "relu"
+}
+
+// LeakyReLU implements the leaky rectified linear unit activation function
+// f(x) = x if x > 0, otherwise alpha * x
+type LeakyReLU struct {
+	// Alpha is the slope used for negative inputs
+	Alpha float64
+}
+
+// Activate applies the leaky ReLU function to the input
+func (l *LeakyReLU) Activate(x float64) float64 {
+	if x > 0 {
+		return x
+	}
+	return l.Alpha * x
+}
+
+// Derivative returns the derivative of the leaky ReLU function at the given input
+// f'(x) = 1 if x > 0, otherwise alpha
+func (l *LeakyReLU) Derivative(x float64) float64 {
+	if x > 0 {
+		return 1.0
+	}
+	return l.Alpha
+}
+
+// Name returns the name of the activation function
+func (l *LeakyReLU) Name() string {
+	return "leaky_relu"
+}
+
+// ELU implements the exponential linear unit activation function
+// f(x) = x if x > 0, otherwise alpha * (e^x - 1)
+type ELU struct {
+	// Alpha controls the value negative inputs saturate to
+	Alpha float64
+}
+
+// Activate applies the ELU function to the input
+func (e *ELU) Activate(x float64) float64 {
+	if x > 0 {
+		return x
+	}
+	return e.Alpha * (math.Exp(x) - 1.0)
+}
+
+// Derivative returns the derivative of the ELU function at the given input
+// f'(x) = 1 if x > 0, otherwise alpha * e^x
+func (e *ELU) Derivative(x float64) float64 {
+	if x > 0 {
+		return 1.0
+	}
+	return e.Alpha * math.Exp(x)
+}
+
+// Name returns the name of the activation function
+func (e *ELU) Name() string {
+	return "elu"
+}
+
+// Softplus implements the softplus activation function, a smooth approximation of ReLU
+// f(x) = ln(1 + e^x)
+type Softplus struct{}
+
+// Activate applies the softplus function to the input
+func (s *Softplus) Activate(x float64) float64 {
+	// For large inputs e^x overflows, but ln(1 + e^x) is x to within float precision
+	if x > 30 {
+		return x
+	}
+	return math.Log1p(math.Exp(x))
+}
+
+// Derivative returns the derivative of the softplus function at the given input
+// f'(x) = 1 / (1 + e^(-x)), which is the sigmoid function
+func (s *Softplus) Derivative(x float64) float64 {
+	return 1.0 / (1.0 + math.Exp(-x))
+}
+
+// Name returns the name of the activation function
+func (s *Softplus) Name() string {
+	return "softplus"
+}
+
+// Linear implements the identity activation function
+// f(x) = x
+type Linear struct{}
+
+// Activate returns the input unchanged
+func (l *Linear) Activate(x float64) float64 {
+	return x
+}
+
+// Derivative returns the derivative of the identity function, which is always 1
+func (l *Linear) Derivative(x float64) float64 {
+	return 1.0
+}
+
+// Name returns the name of the activation function
+func (l *Linear) Name() string {
+	return "linear"
+}
+
+// Softmax implements the softmax activation function for a whole layer
+// f(x)_i = e^(x_i) / sum_j e^(x_j)
+// The outputs of a softmax layer are positive and sum to 1, so it can be used
+// directly as a policy head producing move probabilities
+type Softmax struct{}
+
+// Activate returns the input unchanged
+// Softmax is only meaningful over a whole layer; layers use ActivateVector instead
+func (s *Softmax) Activate(x float64) float64 {
+	return x
+}
+
+// Derivative returns 1, matching Activate
+// Layers use BackwardVector to apply the full softmax Jacobian instead
+func (s *Softmax) Derivative(x float64) float64 {
+	return 1.0
+}
+
+// Name returns the name of the activation function
+func (s *Softmax) Name() string {
+	return "softmax"
+}
+
+// ActivateVector applies the softmax function to the weighted sums
+func (s *Softmax) ActivateVector(sums []float64) []float64 {
+	return OutputToMoveProbabilities(sums)
+}
+
+// BackwardVector applies the softmax Jacobian to the output gradient
+// dL/dx_i = y_i * (dL/dy_i - sum_j dL/dy_j * y_j)
+func (s *Softmax) BackwardVector(outputs, outputGrad []float64) []float64 {
+	dot := 0.0
+	for j, y := range outputs {
+		dot += outputGrad[j] * y
+	}
+
+	grad := make([]float64, len(outputs))
+	for i, y := range outputs {
+		grad[i] = y * (outputGrad[i] - dot)
+	}
+	return grad
+}
+
+// activationConstructors maps activation names to constructors using default parameters
+var activationConstructors = map[string]func() ActivationFunction{
+	"sigmoid":    func() ActivationFunction { return &Sigmoid{} },
+	"tanh":       func() ActivationFunction { return &Tanh{} },
+	"relu":       func() ActivationFunction { return &ReLU{} },
+	"leaky_relu": func() ActivationFunction { return &LeakyReLU{Alpha: 0.01} },
+	"elu":        func() ActivationFunction { return &ELU{Alpha: 1.0} },
+	"softplus":   func() ActivationFunction { return &Softplus{} },
+	"linear":     func() ActivationFunction { return &Linear{} },
+	"softmax":    func() ActivationFunction { return &Softmax{} },
+}
+
+// NewActivation creates an activation function from its Name()
+// Supported names are returned by ActivationNames
+func NewActivation(name string) (ActivationFunction, error) {
+	constructor, ok := activationConstructors[name]
+	if !ok {
+		return nil, fmt.Errorf("unknown activation function %q (available: %v)", name, ActivationNames())
+	}
+	return constructor(), nil
+}
+
+// RegisterActivation makes a custom activation function available to NewActivation
+// Registering a name that already exists replaces the previous constructor
+func RegisterActivation(name string, constructor func() ActivationFunction) {
+	activationConstructors[name] = constructor
+}
+
+// ActivationNames returns the sorted names of all registered activation functions
+func ActivationNames() []string {
+	names := make([]string, 0, len(activationConstructors))
+	for name := range activationConstructors {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
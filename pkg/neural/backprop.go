@@ -0,0 +1,93 @@
+package neural
+
+// Gradients holds the loss gradients for every weight and bias in a network
+// Weights[l][n][w] is the gradient for weight w of neuron n in layer l,
+// and Biases[l][n] is the gradient for the bias of neuron n in layer l
+type Gradients struct {
+	Weights [][][]float64
+	Biases  [][]float64
+}
+
+// NewGradients creates zeroed gradients shaped like the given layers
+func NewGradients(layers []*Layer) *Gradients {
+	grads := &Gradients{
+		Weights: make([][][]float64, len(layers)),
+		Biases:  make([][]float64, len(layers)),
+	}
+
+	for l, layer := range layers {
+		grads.Weights[l] = make([][]float64, len(layer.Neurons))
+		grads.Biases[l] = make([]float64, len(layer.Neurons))
+		for n, neuron := range layer.Neurons {
+			grads.Weights[l][n] = make([]float64, len(neuron.Weights))
+		}
+	}
+
+	return grads
+}
+
+// Add accumulates other into g
+// Both gradients must have the same shape
+func (g *Gradients) Add(other *Gradients) {
+	for l := range g.Weights {
+		for n := range g.Weights[l] {
+			for w := range g.Weights[l][n] {
+				g.Weights[l][n][w] += other.Weights[l][n][w]
+			}
+			g.Biases[l][n] += other.Biases[l][n]
+		}
+	}
+}
+
+// Scale multiplies every gradient by factor
+// This is typically used to average gradients accumulated over a batch
+func (g *Gradients) Scale(factor float64) {
+	for l := range g.Weights {
+		for n := range g.Weights[l] {
+			for w := range g.Weights[l][n] {
+				g.Weights[l][n][w] *= factor
+			}
+			g.Biases[l][n] *= factor
+		}
+	}
+}
+
+// Backward performs a backward pass through the network
+// lossGrad is the gradient of the loss with respect to the network's output
+// from the most recent call to Forward. The gradient is propagated through
+// every layer and the per-weight and per-bias gradients are returned.
+func (n *Network) Backward(lossGrad []float64) *Gradients {
+	_, grads := backward(n.GetLayers(), lossGrad)
+	return grads
+}
+
+// backward propagates outputGrad through layers and returns the gradient with
+// respect to the first layer's input along with the per-layer gradients
+func backward(layers []*Layer, outputGrad []float64) ([]float64, *Gradients) {
+	grads := &Gradients{
+		Weights: make([][][]float64, len(layers)),
+		Biases:  make([][]float64, len(layers)),
+	}
+
+	// Walk the layers in reverse, feeding each layer's input gradient
+	// to the layer before it
+	grad := outputGrad
+	for l := len(layers) - 1; l >= 0; l-- {
+		grad, grads.Weights[l], grads.Biases[l] = layers[l].Backward(grad)
+	}
+
+	return grad, grads
+}
+
+// ApplyGradients performs a plain gradient descent step
+// Every weight and bias is moved against its gradient, scaled by the learning rate
+func (n *Network) ApplyGradients(grads *Gradients, learningRate float64) {
+	for l, layer := range n.GetLayers() {
+		for i, neuron := range layer.Neurons {
+			for j := range neuron.Weights {
+				neuron.Weights[j] -= learningRate * grads.Weights[l][i][j]
+			}
+			neuron.Bias -= learningRate * grads.Biases[l][i]
+		}
+	}
+}
@@ -0,0 +1,158 @@
+package neural
+
+import (
+	"fmt"
+	"sort"
+
+	"github.com/ZachBeta/go_neural_network_learning/pkg/game"
+)
+
+// Encoder converts a board into a network input vector
+// The encoder a network was trained with is saved with it, so inference
+// always feeds the network positions in the scheme it learned from.
+type Encoder interface {
+	// Name returns the name used on the command line and in model files
+	Name() string
+
+	// InputSize returns the length of the input for a board with the given number of cells
+	InputSize(cells int) int
+
+	// Encode converts the board to a network input vector
+	Encode(board game.Grid) []float64
+}
+
+// AbsoluteEncoder encodes each cell as X = 1.0, O = -1.0, Empty = 0.0, like BoardToInput
+// The network has to learn from the position alone which side it is playing.
+type AbsoluteEncoder struct{}
+
+// Name returns the name of the encoder
+func (AbsoluteEncoder) Name() string {
+	return "absolute"
+}
+
+// InputSize returns one value per cell
+func (AbsoluteEncoder) InputSize(cells int) int {
+	return cells
+}
+
+// Encode converts the board to a network input vector
+func (AbsoluteEncoder) Encode(board game.Grid) []float64 {
+	return BoardToInput(board)
+}
+
+// PerspectiveEncoder encodes each cell from the side of the player to move:
+// own stones = 1.0, opponent stones = -1.0, Empty = 0.0
+// X and O positions that mirror each other look the same, so one policy serves both sides.
+type PerspectiveEncoder struct{}
+
+// Name returns the name of the encoder
+func (PerspectiveEncoder) Name() string {
+	return "perspective"
+}
+
+// InputSize returns one value per cell
+func (PerspectiveEncoder) InputSize(cells int) int {
+	return cells
+}
+
+// Encode converts the board to a network input vector
+func (PerspectiveEncoder) Encode(board game.Grid) []float64 {
+	input := BoardToInput(board)
+	if board.GetCurrentPlayer() == game.O {
+		for i := range input {
+			input[i] = -input[i]
+		}
+	}
+	return input
+}
+
+// PlaneEncoder encodes the board as four one-hot planes of one value per cell:
+// the stones of the player to move, the opponent's stones, the empty cells and
+// a plane that is 1.0 everywhere when X is to move and 0.0 when O is
+type PlaneEncoder struct{}
+
+// Name returns the name of the encoder
+func (PlaneEncoder) Name() string {
+	return "planes"
+}
+
+// InputSize returns four values per cell
+func (PlaneEncoder) InputSize(cells int) int {
+	return 4 * cells
+}
+
+// Encode converts the board to a network input vector
+func (PlaneEncoder) Encode(board game.Grid) []float64 {
+	width := board.Width()
+	cells := width * board.Height()
+	input := make([]float64, 4*cells)
+	player := board.GetCurrentPlayer()
+
+	for i := 0; i < cells; i++ {
+		row, col := IndexToRowCol(i, width)
+		switch board.Get(row, col) {
+		case game.Empty:
+			input[2*cells+i] = 1.0
+		case player:
+			input[i] = 1.0
+		default:
+			input[cells+i] = 1.0
+		}
+		if player == game.X {
+			input[3*cells+i] = 1.0
+		}
+	}
+
+	return input
+}
+
+// encoderConstructors maps encoder names to constructors
+var encoderConstructors = map[string]func() Encoder{
+	"absolute":    func() Encoder { return AbsoluteEncoder{} },
+	"perspective": func() Encoder { return PerspectiveEncoder{} },
+	"planes":      func() Encoder { return PlaneEncoder{} },
+}
+
+// NewEncoder creates an encoder from its Name()
+// Supported names are returned by EncoderNames
+func NewEncoder(name string) (Encoder, error) {
+	constructor, ok := encoderConstructors[name]
+	if !ok {
+		return nil, fmt.Errorf("unknown encoder %q (available: %v)", name, EncoderNames())
+	}
+	return constructor(), nil
+}
+
+// RegisterEncoder makes a custom encoder available to NewEncoder
+// Registering a name that already exists replaces the previous constructor
+func RegisterEncoder(name string, constructor func() Encoder) {
+	encoderConstructors[name] = constructor
+}
+
+// EncoderNames returns the sorted names of all registered encoders
+func EncoderNames() []string {
+	names := make([]string, 0, len(encoderConstructors))
+	for name := range encoderConstructors {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// Model is a network that knows its input and output sizes and the encoder it was
+// trained with; Network and PolicyValueNetwork implement it
+type Model interface {
+	GetInputSize() int
+	GetOutputSize() int
+	GetEncoder() Encoder
+}
+
+// EncodeState converts a game state to the input the network expects
+// Boards are encoded with the network's encoder; states that are not boards,
+// such as Connect Four, always use their own Encode.
+func EncodeState(network Model, state game.State) []float64 {
+	if board, ok := state.(game.Grid); ok {
+		return network.GetEncoder().Encode(board)
+	}
+	return state.Encode()
+}
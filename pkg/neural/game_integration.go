@@ -1,20 +1,22 @@
 package neural
 
 import (
+	"fmt"
 	"math"
 
 	"github.com/ZachBeta/go_neural_network_learning/pkg/game"
 )
 
 // BoardToInput converts a game board to a neural network input vector
-// The input vector is a flattened representation of the board
+// The input vector is a flattened representation of the board, one value per cell
+// in row-major order, so it works for any board size
 // X = 1.0, O = -1.0, Empty = 0.0
-func BoardToInput(board *game.Board) []float64 {
-	input := make([]float64, 9)
+func BoardToInput(board game.Grid) []float64 {
+	width := board.Width()
+	input := make([]float64, width*board.Height())
 
-	for i := 0; i < 9; i++ {
-		row := i / 3
-		col := i % 3
+	for i := range input {
+		row, col := IndexToRowCol(i, width)
 
 		switch board.Get(row, col) {
 		case game.X:
@@ -60,6 +62,152 @@ func OutputToMoveProbabilities(output []float64) []float64 {
 	return probabilities
 }
 
+// PredictLegalMoveProbabilities runs the network on a board and returns move probabilities
+// restricted to the board's legal moves; occupied cells always get probability 0
+func PredictLegalMoveProbabilities(network *Network, board game.Grid) []float64 {
+	return PredictMaskedProbabilities(network, network.GetEncoder().Encode(board), LegalMoveMask(board))
+}
+
+// PredictStateProbabilities runs the network on any game state and returns move
+// probabilities restricted to the state's legal moves
+func PredictStateProbabilities(network *Network, state game.State) []float64 {
+	return PredictMaskedProbabilities(network, EncodeState(network, state), StateMoveMask(state))
+}
+
+// StateMoveMask returns a mask over all of a state's moves with true for every legal move
+func StateMoveMask(state game.State) []bool {
+	mask := make([]bool, state.ActionSize())
+	for _, move := range state.LegalMoves() {
+		mask[move] = true
+	}
+	return mask
+}
+
+// CheckNetworkFits returns an error if the network's encoder or its input and output
+// sizes don't match the game
+func CheckNetworkFits(network Model, g game.Game) error {
+	inputSize, err := GameInputSize(network.GetEncoder(), g)
+	if err != nil {
+		return err
+	}
+	if network.GetInputSize() != inputSize || network.GetOutputSize() != g.ActionSize() {
+		return fmt.Errorf("network has %d inputs and %d outputs; %s with the %s encoder needs %d and %d",
+			network.GetInputSize(), network.GetOutputSize(), g.Name(), network.GetEncoder().Name(), inputSize, g.ActionSize())
+	}
+	return nil
+}
+
+// GameInputSize returns the network input size for a game encoded with encoder
+// Games whose states are not boards have their own encoding and only work with AbsoluteEncoder.
+func GameInputSize(encoder Encoder, g game.Game) (int, error) {
+	board, ok := g.NewState().(game.Grid)
+	if !ok {
+		if _, absolute := encoder.(AbsoluteEncoder); !absolute {
+			return 0, fmt.Errorf("%s has its own input encoding and cannot use the %s encoder", g.Name(), encoder.Name())
+		}
+		return g.InputSize(), nil
+	}
+	return encoder.InputSize(board.Width() * board.Height()), nil
+}
+
+// PredictMaskedProbabilities runs the network on an already encoded input and returns
+// move probabilities restricted to the moves allowed by mask
+// It lets games with their own encoding and move numbering, such as Connect Four, use the network
+func PredictMaskedProbabilities(network *Network, input []float64, mask []bool) []float64 {
+	output := network.Forward(input)
+	if _, ok := network.GetOutputLayer().GetNeuron(0).Activation.(*Softmax); ok {
+		return MaskProbabilities(output, mask)
+	}
+	return MaskedSoftmax(output, mask)
+}
+
+// LegalMoveMask returns a mask with true for every move index that is legal on the board
+func LegalMoveMask(board game.Grid) []bool {
+	mask := make([]bool, board.Width()*board.Height())
+	for _, move := range board.LegalMoves() {
+		mask[move] = true
+	}
+	return mask
+}
+
+// MaskedSoftmax converts neural network output to probabilities over the legal moves only
+// It works like OutputToMoveProbabilities, but illegal moves get probability 0 and
+// the legal moves sum to 1. If no move is legal every probability is 0.
+func MaskedSoftmax(output []float64, mask []bool) []float64 {
+	probabilities := make([]float64, len(output))
+
+	// Find the maximum legal value for numerical stability
+	maxVal := math.Inf(-1)
+	for i, val := range output {
+		if mask[i] && val > maxVal {
+			maxVal = val
+		}
+	}
+	if math.IsInf(maxVal, -1) {
+		return probabilities
+	}
+
+	// Calculate sum of exponentials over legal moves
+	sum := 0.0
+	for i, val := range output {
+		if mask[i] {
+			probabilities[i] = math.Exp(val - maxVal)
+			sum += probabilities[i]
+		}
+	}
+
+	// Normalize to get probabilities
+	for i := range probabilities {
+		probabilities[i] /= sum
+	}
+
+	return probabilities
+}
+
+// MaskProbabilities zeroes the probabilities of illegal moves and renormalizes the rest
+// If the legal moves have no probability mass they share it uniformly
+func MaskProbabilities(probabilities []float64, mask []bool) []float64 {
+	masked := make([]float64, len(probabilities))
+
+	sum := 0.0
+	legal := 0
+	for i, prob := range probabilities {
+		if mask[i] {
+			masked[i] = prob
+			sum += prob
+			legal++
+		}
+	}
+
+	for i := range masked {
+		if !mask[i] {
+			continue
+		}
+		if sum > 0 {
+			masked[i] /= sum
+		} else {
+			masked[i] = 1.0 / float64(legal)
+		}
+	}
+
+	return masked
+}
+
+// SelectBestLegalMove selects the legal move with the highest probability
+// It returns the index of the best move, or -1 if no move is legal
+func SelectBestLegalMove(probabilities []float64, mask []bool) int {
+	bestMove := -1
+	for i, prob := range probabilities {
+		if !mask[i] {
+			continue
+		}
+		if bestMove == -1 || prob > probabilities[bestMove] {
+			bestMove = i
+		}
+	}
+	return bestMove
+}
+
 // SelectBestMove selects the best move based on move probabilities
 // It returns the index of the best move (0-8)
 func SelectBestMove(probabilities []float64) int {
@@ -78,12 +226,20 @@ func SelectBestMove(probabilities []float64) int {
 
 // MoveIndexToRowCol converts a move index (0-8) to row and column coordinates
 func MoveIndexToRowCol(moveIndex int) (row, col int) {
-	row = moveIndex / 3
-	col = moveIndex % 3
-	return row, col
+	return IndexToRowCol(moveIndex, 3)
 }
 
 // RowColToMoveIndex converts row and column coordinates to a move index (0-8)
 func RowColToMoveIndex(row, col int) int {
-	return row*3 + col
+	return RowColToIndex(row, col, 3)
+}
+
+// IndexToRowCol converts a move index to row and column coordinates on a board of the given width
+func IndexToRowCol(moveIndex, width int) (row, col int) {
+	return moveIndex / width, moveIndex % width
+}
+
+// RowColToIndex converts row and column coordinates to a move index on a board of the given width
+func RowColToIndex(row, col, width int) int {
+	return row*width + col
 }
@@ -7,6 +7,13 @@ type Layer struct {
 
 	// Output is the output of the layer after a forward pass
 	Output []float64
+
+	// Input is a copy of the input seen during the last forward pass
+	Input []float64
+
+	// Sums are the pre-activation weighted sums from the last forward pass
+	// They are needed to evaluate activation derivatives during backpropagation
+	Sums []float64
 }
 
 // NewLayer creates a new layer with the specified number of neurons and inputs
@@ -14,6 +21,7 @@ func NewLayer(neuronCount, inputSize int, activation ActivationFunction) *Layer
 	layer := &Layer{
 		Neurons: make([]*Neuron, neuronCount),
 		Output:  make([]float64, neuronCount),
+		Sums:    make([]float64, neuronCount),
 	}
 
 	// Create neurons
@@ -27,14 +35,75 @@ func NewLayer(neuronCount, inputSize int, activation ActivationFunction) *Layer
 // Forward performs a forward pass through the layer
 // It processes the input through all neurons in the layer
 func (l *Layer) Forward(input []float64) []float64 {
+	if len(l.Sums) != len(l.Neurons) {
+		l.Sums = make([]float64, len(l.Neurons))
+	}
+
+	// Remember the input for the backward pass
+	l.Input = append(l.Input[:0], input...)
+
+	// Layer-wide activations such as softmax need every weighted sum first
+	if activation, ok := l.vectorActivation(); ok {
+		for i, neuron := range l.Neurons {
+			l.Sums[i] = neuron.WeightedSum(input)
+		}
+		copy(l.Output, activation.ActivateVector(l.Sums))
+		return l.Output
+	}
+
 	// Process input through all neurons
 	for i, neuron := range l.Neurons {
-		l.Output[i] = neuron.Forward(input)
+		l.Sums[i] = neuron.WeightedSum(input)
+		l.Output[i] = neuron.Activation.Activate(l.Sums[i])
 	}
 
 	return l.Output
 }
 
+// vectorActivation returns the layer's activation if it acts on the whole layer at once
+func (l *Layer) vectorActivation() (VectorActivation, bool) {
+	if len(l.Neurons) == 0 {
+		return nil, false
+	}
+	activation, ok := l.Neurons[0].Activation.(VectorActivation)
+	return activation, ok
+}
+
+// Backward performs a backward pass through the layer
+// outputGrad is the gradient of the loss with respect to each neuron's output.
+// It returns the gradient with respect to the layer's input along with the
+// per-weight and per-bias gradients for every neuron in the layer.
+// Backward must be called after Forward, since it relies on the stored input and sums.
+func (l *Layer) Backward(outputGrad []float64) (inputGrad []float64, weightGrads [][]float64, biasGrads []float64) {
+	inputGrad = make([]float64, len(l.Input))
+	weightGrads = make([][]float64, len(l.Neurons))
+	biasGrads = make([]float64, len(l.Neurons))
+
+	// Chain rule through the activation function
+	var deltas []float64
+	if activation, ok := l.vectorActivation(); ok {
+		deltas = activation.BackwardVector(l.Output, outputGrad)
+	} else {
+		deltas = make([]float64, len(l.Neurons))
+		for i, neuron := range l.Neurons {
+			deltas[i] = outputGrad[i] * neuron.Activation.Derivative(l.Sums[i])
+		}
+	}
+
+	for i, neuron := range l.Neurons {
+		delta := deltas[i]
+
+		weightGrads[i] = make([]float64, len(neuron.Weights))
+		for j, weight := range neuron.Weights {
+			weightGrads[i][j] = delta * l.Input[j]
+			inputGrad[j] += delta * weight
+		}
+		biasGrads[i] = delta
+	}
+
+	return inputGrad, weightGrads, biasGrads
+}
+
 // GetNeurons returns a copy of the layer's neurons
 func (l *Layer) GetNeurons() []*Neuron {
 	neurons := make([]*Neuron, len(l.Neurons))
@@ -1,33 +1,85 @@
 package neural
 
-// Network represents a simple neural network with input and output layers
+import (
+	"fmt"
+)
+
+// Network represents a feed-forward neural network made of one or more layers
 type Network struct {
-	// InputLayer is the input layer of the network
-	InputLayer *Layer
+	// Layers are the layers of the network in forward order
+	// The first layer receives the raw input and the last layer produces the output
+	Layers []*Layer
 
 	// OutputLayer is the output layer of the network
+	// It always refers to the last element of Layers
 	OutputLayer *Layer
+
+	// Encoder converts boards into the input the network was trained on
+	// A nil Encoder means AbsoluteEncoder, the encoding of BoardToInput
+	Encoder Encoder
 }
 
 // NewNetwork creates a new neural network with the specified input and output sizes
 func NewNetwork(inputSize, outputSize int) *Network {
-	// Create a simple network with just input and output layers
-	// For a single-layer perceptron, the input layer is just a pass-through
-	// and the output layer is the actual layer of neurons
+	// Create a simple network with a single layer of neurons
+	// For a single-layer perceptron, the input is passed straight to the output layer
+	network, _ := NewMultiLayerNetwork([]int{inputSize, outputSize}, []ActivationFunction{&Sigmoid{}})
+	return network
+}
 
-	network := &Network{}
+// NewMultiLayerNetwork creates a neural network with the given topology
+// layerSizes lists the input size followed by the number of neurons in each layer,
+// e.g. []int{9, 64, 32, 9} creates two hidden layers and a 9-neuron output layer.
+// activations holds one activation function per layer (len(layerSizes)-1 entries);
+// a nil entry defaults to sigmoid.
+func NewMultiLayerNetwork(layerSizes []int, activations []ActivationFunction) (*Network, error) {
+	if len(layerSizes) < 2 {
+		return nil, fmt.Errorf("network needs an input size and at least one layer, got %d sizes", len(layerSizes))
+	}
+	if len(activations) != len(layerSizes)-1 {
+		return nil, fmt.Errorf("expected %d activation functions, got %d", len(layerSizes)-1, len(activations))
+	}
+	for i, size := range layerSizes {
+		if size <= 0 {
+			return nil, fmt.Errorf("layer size at index %d must be positive, got %d", i, size)
+		}
+	}
 
-	// Create output layer with sigmoid activation
-	network.OutputLayer = NewLayer(outputSize, inputSize, &Sigmoid{})
+	network := &Network{
+		Layers: make([]*Layer, len(layerSizes)-1),
+	}
 
-	return network
+	// Each layer takes the previous layer's output as its input
+	for i := 1; i < len(layerSizes); i++ {
+		network.Layers[i-1] = NewLayer(layerSizes[i], layerSizes[i-1], activations[i-1])
+	}
+	network.OutputLayer = network.Layers[len(network.Layers)-1]
+
+	return network, nil
 }
 
 // Forward performs a forward pass through the network
 // It processes the input through all layers in the network
 func (n *Network) Forward(input []float64) []float64 {
-	// For a single-layer perceptron, we just pass the input to the output layer
-	return n.OutputLayer.Forward(input)
+	output := input
+	for _, layer := range n.GetLayers() {
+		output = layer.Forward(output)
+	}
+	return output
+}
+
+// GetLayers returns the layers of the network in forward order
+// Networks built by hand with only an OutputLayer are treated as single-layer networks
+func (n *Network) GetLayers() []*Layer {
+	if len(n.Layers) == 0 && n.OutputLayer != nil {
+		return []*Layer{n.OutputLayer}
+	}
+	return n.Layers
+}
+
+// GetLayerCount returns the number of layers in the network
+func (n *Network) GetLayerCount() int {
+	return len(n.GetLayers())
 }
 
 // GetOutputLayer returns the output layer of the network
@@ -35,6 +87,50 @@ func (n *Network) GetOutputLayer() *Layer {
 	return n.OutputLayer
 }
 
+// GetInputSize returns the number of inputs the network expects
+func (n *Network) GetInputSize() int {
+	return len(n.GetLayers()[0].GetNeuron(0).Weights)
+}
+
+// GetEncoder returns the encoder for the network's input, AbsoluteEncoder if none was set
+func (n *Network) GetEncoder() Encoder {
+	if n.Encoder == nil {
+		return AbsoluteEncoder{}
+	}
+	return n.Encoder
+}
+
+// GetOutputSize returns the number of outputs the network produces
+func (n *Network) GetOutputSize() int {
+	return n.OutputLayer.GetNeuronCount()
+}
+
+// Clone returns a copy of the network with its own weights
+// The encoder is shared and optimizer state is not copied.
+func (n *Network) Clone() *Network {
+	clone := &Network{
+		Layers:  make([]*Layer, len(n.GetLayers())),
+		Encoder: n.Encoder,
+	}
+	for l, layer := range n.GetLayers() {
+		copied := &Layer{
+			Neurons: make([]*Neuron, len(layer.Neurons)),
+			Output:  make([]float64, len(layer.Neurons)),
+			Sums:    make([]float64, len(layer.Neurons)),
+		}
+		for i, neuron := range layer.Neurons {
+			copied.Neurons[i] = &Neuron{
+				Weights:    neuron.GetWeights(),
+				Bias:       neuron.Bias,
+				Activation: neuron.Activation,
+			}
+		}
+		clone.Layers[l] = copied
+	}
+	clone.OutputLayer = clone.Layers[len(clone.Layers)-1]
+	return clone
+}
+
 // GetOutput returns the output of the network after a forward pass
 func (n *Network) GetOutput() []float64 {
 	return n.OutputLayer.GetOutput()
@@ -1,8 +1,16 @@
 package neural
 
 import (
+	"bytes"
+	"errors"
+	"fmt"
 	"math"
+	"math/rand"
+	"strings"
 	"testing"
+
+	"github.com/ZachBeta/go_neural_network_learning/internal/utils"
+	"github.com/ZachBeta/go_neural_network_learning/pkg/game"
 )
 
 func TestSigmoidActivation(t *testing.T) {
@@ -165,3 +173,615 @@ func TestOutputToMoveProbabilities(t *testing.T) {
 		t.Errorf("Probabilities not in correct order: %v", probabilities)
 	}
 }
+
+func TestMultiLayerNetworkForward(t *testing.T) {
+	network, err := NewMultiLayerNetwork([]int{9, 64, 32, 9}, []ActivationFunction{&Sigmoid{}, &Sigmoid{}, &Sigmoid{}})
+	if err != nil {
+		t.Fatalf("NewMultiLayerNetwork returned error: %v", err)
+	}
+
+	if network.GetLayerCount() != 3 {
+		t.Errorf("GetLayerCount() = %d, want 3", network.GetLayerCount())
+	}
+	if network.GetOutputLayer() != network.Layers[2] {
+		t.Error("GetOutputLayer() should return the last layer")
+	}
+
+	// Check that each layer is wired to the previous layer's output
+	expectedInputs := []int{9, 64, 32}
+	for i, layer := range network.Layers {
+		if len(layer.GetNeuron(0).Weights) != expectedInputs[i] {
+			t.Errorf("layer %d has %d inputs, want %d", i, len(layer.GetNeuron(0).Weights), expectedInputs[i])
+		}
+	}
+
+	output := network.Forward(make([]float64, 9))
+	if len(output) != 9 {
+		t.Fatalf("Forward returned %d outputs, want 9", len(output))
+	}
+	for i, val := range network.GetOutput() {
+		if val != output[i] {
+			t.Errorf("GetOutput()[%d] = %v, want %v", i, val, output[i])
+		}
+	}
+
+	// Mismatched topology should be rejected
+	if _, err := NewMultiLayerNetwork([]int{9, 9}, nil); err == nil {
+		t.Error("expected error for missing activation functions")
+	}
+}
+
+func TestNetworkBackward(t *testing.T) {
+	SetRandomSeed(42)
+	network, err := NewMultiLayerNetwork([]int{3, 4, 2}, []ActivationFunction{&Sigmoid{}, &Sigmoid{}})
+	if err != nil {
+		t.Fatalf("NewMultiLayerNetwork returned error: %v", err)
+	}
+
+	input := []float64{0.5, -1.0, 0.25}
+	target := []float64{1.0, 0.0}
+	loss := func() float64 {
+		return CalculateMSE(network.Forward(input), target)
+	}
+
+	output := network.Forward(input)
+	grads := network.Backward(MSEGradient(output, target))
+
+	// Compare analytic gradients against central finite differences
+	const h = 1e-6
+	for l, layer := range network.Layers {
+		for n, neuron := range layer.Neurons {
+			for w := range neuron.Weights {
+				original := neuron.Weights[w]
+				neuron.Weights[w] = original + h
+				plus := loss()
+				neuron.Weights[w] = original - h
+				minus := loss()
+				neuron.Weights[w] = original
+
+				numeric := (plus - minus) / (2 * h)
+				if math.Abs(numeric-grads.Weights[l][n][w]) > 1e-6 {
+					t.Errorf("weight gradient [%d][%d][%d] = %v, want %v", l, n, w, grads.Weights[l][n][w], numeric)
+				}
+			}
+
+			original := neuron.Bias
+			neuron.Bias = original + h
+			plus := loss()
+			neuron.Bias = original - h
+			minus := loss()
+			neuron.Bias = original
+
+			numeric := (plus - minus) / (2 * h)
+			if math.Abs(numeric-grads.Biases[l][n]) > 1e-6 {
+				t.Errorf("bias gradient [%d][%d] = %v, want %v", l, n, grads.Biases[l][n], numeric)
+			}
+		}
+	}
+
+	// A gradient descent step should reduce the loss
+	before := loss()
+	network.Forward(input)
+	network.ApplyGradients(grads, 0.5)
+	if after := loss(); after >= before {
+		t.Errorf("loss did not decrease after gradient step: before %v, after %v", before, after)
+	}
+}
+
+func TestOptimizersReduceLoss(t *testing.T) {
+	input := []float64{0.5, -1.0, 0.25}
+	target := []float64{1.0, 0.0}
+
+	for _, name := range OptimizerNames() {
+		t.Run(name, func(t *testing.T) {
+			SetRandomSeed(7)
+			network, err := NewMultiLayerNetwork([]int{3, 4, 2}, []ActivationFunction{&Sigmoid{}, &Sigmoid{}})
+			if err != nil {
+				t.Fatalf("NewMultiLayerNetwork returned error: %v", err)
+			}
+			optimizer, err := NewOptimizer(name, 0.05)
+			if err != nil {
+				t.Fatalf("NewOptimizer(%q) returned error: %v", name, err)
+			}
+			if optimizer.Name() != name {
+				t.Errorf("Name() = %q, want %q", optimizer.Name(), name)
+			}
+
+			before := CalculateMSE(network.Forward(input), target)
+			for i := 0; i < 50; i++ {
+				output := network.Forward(input)
+				optimizer.Step(network.Layers, network.Backward(MSEGradient(output, target)))
+			}
+			after := CalculateMSE(network.Forward(input), target)
+
+			if after >= before {
+				t.Errorf("loss did not decrease: before %v, after %v", before, after)
+			}
+			if network.OutputLayer.GetNeuron(0).OptimizerState == nil {
+				t.Error("optimizer state was not stored on the neuron")
+			}
+		})
+	}
+
+	if _, err := NewOptimizer("unknown", 0.1); err == nil {
+		t.Error("expected error for unknown optimizer")
+	}
+}
+
+func TestActivationDerivatives(t *testing.T) {
+	const h = 1e-6
+	inputs := []float64{-2.0, -0.5, 0.3, 1.5}
+
+	for _, name := range ActivationNames() {
+		activation, err := NewActivation(name)
+		if err != nil {
+			t.Fatalf("NewActivation(%q) returned error: %v", name, err)
+		}
+		if activation.Name() != name {
+			t.Errorf("NewActivation(%q).Name() = %q", name, activation.Name())
+		}
+		if _, ok := activation.(VectorActivation); ok {
+			continue
+		}
+
+		for _, x := range inputs {
+			numeric := (activation.Activate(x+h) - activation.Activate(x-h)) / (2 * h)
+			if math.Abs(numeric-activation.Derivative(x)) > 1e-5 {
+				t.Errorf("%s derivative at %v = %v, want %v", name, x, activation.Derivative(x), numeric)
+			}
+		}
+	}
+
+	if _, err := NewActivation("unknown"); err == nil {
+		t.Error("expected error for unknown activation")
+	}
+}
+
+func TestSoftmaxLayerBackward(t *testing.T) {
+	SetRandomSeed(3)
+	network, err := NewMultiLayerNetwork([]int{3, 4, 3}, []ActivationFunction{&Tanh{}, &Softmax{}})
+	if err != nil {
+		t.Fatalf("NewMultiLayerNetwork returned error: %v", err)
+	}
+
+	input := []float64{0.2, -0.7, 1.1}
+	target := []float64{0.0, 1.0, 0.0}
+	loss := func() float64 {
+		return CalculateCrossEntropy(network.Forward(input), target)
+	}
+
+	output := network.Forward(input)
+	sum := 0.0
+	for _, p := range output {
+		sum += p
+	}
+	if math.Abs(sum-1.0) > 1e-10 {
+		t.Errorf("softmax outputs sum to %v, want 1.0", sum)
+	}
+
+	grads := network.Backward(CrossEntropyGradient(output, target))
+
+	// With softmax and cross-entropy the output layer's bias gradient is p - t
+	for i, p := range output {
+		if math.Abs(grads.Biases[1][i]-(p-target[i])) > 1e-9 {
+			t.Errorf("output bias gradient [%d] = %v, want %v", i, grads.Biases[1][i], p-target[i])
+		}
+	}
+
+	// Check the hidden layer against finite differences
+	const h = 1e-6
+	for n, neuron := range network.Layers[0].Neurons {
+		for w := range neuron.Weights {
+			original := neuron.Weights[w]
+			neuron.Weights[w] = original + h
+			plus := loss()
+			neuron.Weights[w] = original - h
+			minus := loss()
+			neuron.Weights[w] = original
+
+			numeric := (plus - minus) / (2 * h)
+			if math.Abs(numeric-grads.Weights[0][n][w]) > 1e-6 {
+				t.Errorf("hidden weight gradient [%d][%d] = %v, want %v", n, w, grads.Weights[0][n][w], numeric)
+			}
+		}
+	}
+}
+
+func TestSaveLoadModel(t *testing.T) {
+	SetRandomSeed(11)
+	network, err := NewMultiLayerNetwork([]int{9, 6, 9}, []ActivationFunction{&LeakyReLU{Alpha: 0.2}, &Softmax{}})
+	if err != nil {
+		t.Fatalf("NewMultiLayerNetwork returned error: %v", err)
+	}
+	optimizer := NewAdam(0.01)
+
+	// Take a step so the optimizer has state worth saving
+	input := []float64{1, 0, -1, 0, 1, 0, 0, 0, -1}
+	target := []float64{0, 1, 0, 0, 0, 0, 0, 0, 0}
+	output := network.Forward(input)
+	optimizer.Step(network.Layers, network.Backward(CrossEntropyGradient(output, target)))
+
+	path := t.TempDir() + "/model.json"
+	if err := SaveModel(path, network, optimizer); err != nil {
+		t.Fatalf("SaveModel returned error: %v", err)
+	}
+
+	loaded, loadedOptimizer, err := LoadModel(path)
+	if err != nil {
+		t.Fatalf("LoadModel returned error: %v", err)
+	}
+
+	expected := network.Forward(input)
+	actual := loaded.Forward(input)
+	for i := range expected {
+		if math.Abs(expected[i]-actual[i]) > 1e-12 {
+			t.Errorf("loaded output[%d] = %v, want %v", i, actual[i], expected[i])
+		}
+	}
+
+	if alpha := loaded.Layers[0].GetNeuron(0).Activation.(*LeakyReLU).Alpha; alpha != 0.2 {
+		t.Errorf("loaded LeakyReLU alpha = %v, want 0.2", alpha)
+	}
+
+	adam, ok := loadedOptimizer.(*Adam)
+	if !ok {
+		t.Fatalf("loaded optimizer has type %T, want *Adam", loadedOptimizer)
+	}
+	if adam.StepCount != 1 || adam.LearningRate != 0.01 {
+		t.Errorf("loaded Adam = %+v, want step 1 and learning rate 0.01", adam)
+	}
+
+	state := loaded.OutputLayer.GetNeuron(1).OptimizerState
+	original := network.OutputLayer.GetNeuron(1).OptimizerState
+	if state == nil || state.BiasMoment != original.BiasMoment || state.Moment[0] != original.Moment[0] {
+		t.Error("optimizer state was not restored")
+	}
+}
+
+func TestMaskedMoveSelection(t *testing.T) {
+	utils.SetLogLevel(utils.ERROR)
+	board := game.NewBoard()
+	board.MakeMove(1, 1)
+	board.MakeMove(0, 0)
+
+	mask := LegalMoveMask(board)
+	if mask[4] || mask[0] || !mask[8] {
+		t.Errorf("LegalMoveMask() = %v, occupied cells must be masked", mask)
+	}
+
+	// The occupied center has by far the highest output
+	output := []float64{5, 0, 0, 0, 10, 0, 0, 1, 0}
+	probabilities := MaskedSoftmax(output, mask)
+	sum := 0.0
+	for i, prob := range probabilities {
+		if !mask[i] && prob != 0 {
+			t.Errorf("illegal move %d has probability %v", i, prob)
+		}
+		sum += prob
+	}
+	if math.Abs(sum-1.0) > 1e-10 {
+		t.Errorf("masked probabilities sum to %v, want 1.0", sum)
+	}
+	if move := SelectBestLegalMove(probabilities, mask); move != 7 {
+		t.Errorf("SelectBestLegalMove() = %d, want 7", move)
+	}
+
+	masked := MaskProbabilities([]float64{0.5, 0.1, 0.1, 0.1, 0.2, 0, 0, 0, 0}, mask)
+	if math.Abs(masked[1]-1.0/3.0) > 1e-10 || masked[0] != 0 {
+		t.Errorf("MaskProbabilities() = %v, want legal mass renormalized", masked)
+	}
+
+	// A network never picks an occupied cell
+	network := NewNetwork(9, 9)
+	move := SelectBestLegalMove(PredictLegalMoveProbabilities(network, board), mask)
+	if !mask[move] {
+		t.Errorf("network selected illegal move %d", move)
+	}
+
+	if move := SelectBestLegalMove(probabilities, make([]bool, 9)); move != -1 {
+		t.Errorf("SelectBestLegalMove() with no legal moves = %d, want -1", move)
+	}
+}
+
+func TestSampleMove(t *testing.T) {
+	probabilities := []float64{0.1, 0, 0.6, 0, 0.2, 0, 0.1, 0, 0}
+	rng := rand.New(rand.NewSource(1))
+
+	// Temperature 0 is greedy
+	for i := 0; i < 20; i++ {
+		if move := SampleMove(probabilities, SamplingOptions{}, rng); move != 2 {
+			t.Fatalf("SampleMove() at temperature 0 = %d, want 2", move)
+		}
+	}
+
+	// Moves with probability 0 are never sampled, at any temperature
+	counts := make([]int, 9)
+	for i := 0; i < 2000; i++ {
+		counts[SampleMove(probabilities, SamplingOptions{Temperature: 5}, rng)]++
+	}
+	for i, prob := range probabilities {
+		if prob == 0 && counts[i] > 0 {
+			t.Errorf("move %d with probability 0 sampled %d times", i, counts[i])
+		}
+		if prob > 0 && counts[i] == 0 {
+			t.Errorf("move %d with probability %v never sampled", i, prob)
+		}
+	}
+
+	// Higher temperatures flatten the distribution
+	hot := ApplyTemperature(probabilities, 10)
+	cold := ApplyTemperature(probabilities, 0.5)
+	if !(hot[2] < probabilities[2] && probabilities[2] < cold[2]) {
+		t.Errorf("ApplyTemperature() best move: T=10 %v, T=1 %v, T=0.5 %v", hot[2], probabilities[2], cold[2])
+	}
+
+	tests := []struct {
+		name string
+		topK int
+		topP float64
+		want []int
+	}{
+		{"top-k 1", 1, 0, []int{2}},
+		{"top-k 2", 2, 0, []int{2, 4}},
+		{"top-p 0.5", 0, 0.5, []int{2}},
+		{"top-p 0.7", 0, 0.7, []int{2, 4}},
+		{"top-p 0.85", 0, 0.85, []int{2, 4, 0}},
+		{"top-k wins over top-p", 1, 0.9, []int{2}},
+		{"no truncation", 0, 0, []int{0, 2, 4, 6}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			truncated := TruncateProbabilities(probabilities, tt.topK, tt.topP)
+			kept := 0
+			for _, prob := range truncated {
+				if prob > 0 {
+					kept++
+				}
+			}
+			for _, move := range tt.want {
+				if truncated[move] == 0 {
+					t.Errorf("move %d was truncated: %v", move, truncated)
+				}
+			}
+			if kept != len(tt.want) {
+				t.Errorf("kept %d moves, want %d: %v", kept, len(tt.want), truncated)
+			}
+		})
+	}
+
+	schedule := StepTemperature(1.0, 0.1, 3)
+	if schedule(0) != 1.0 || schedule(2) != 1.0 || schedule(3) != 0.1 {
+		t.Errorf("StepTemperature() = %v, %v, %v", schedule(0), schedule(2), schedule(3))
+	}
+
+	if move := SampleMove(make([]float64, 9), SamplingOptions{Temperature: 1}, rng); move != -1 {
+		t.Errorf("SampleMove() with no probability = %d, want -1", move)
+	}
+}
+
+func TestLargerBoards(t *testing.T) {
+	utils.SetLogLevel(utils.ERROR)
+
+	// 4x4 board with 3 in a row: X at (1,2), O at (3,0)
+	board, err := game.NewMNKBoard(4, 4, 3)
+	if err != nil {
+		t.Fatalf("NewMNKBoard returned error: %v", err)
+	}
+	board.MakeMove(1, 2)
+	board.MakeMove(3, 0)
+
+	input := BoardToInput(board)
+	if len(input) != 16 {
+		t.Fatalf("BoardToInput() has %d values, want 16", len(input))
+	}
+	if input[RowColToIndex(1, 2, 4)] != 1.0 || input[RowColToIndex(3, 0, 4)] != -1.0 {
+		t.Errorf("BoardToInput() = %v", input)
+	}
+	if row, col := IndexToRowCol(14, 4); row != 3 || col != 2 {
+		t.Errorf("IndexToRowCol(14, 4) = %d, %d; want 3, 2", row, col)
+	}
+
+	network, err := NewMultiLayerNetwork([]int{16, 8, 16}, []ActivationFunction{&Tanh{}, &Softmax{}})
+	if err != nil {
+		t.Fatalf("NewMultiLayerNetwork returned error: %v", err)
+	}
+	mask := LegalMoveMask(board)
+	move := SelectBestLegalMove(PredictLegalMoveProbabilities(network, board), mask)
+	if move < 0 || move >= 16 || !mask[move] || move == 6 || move == 12 {
+		t.Errorf("network selected illegal move %d", move)
+	}
+}
+
+func TestEncoders(t *testing.T) {
+	utils.SetLogLevel(utils.ERROR)
+
+	// X at 0, O at 4; X to move, then O to move after X plays 8
+	board := game.NewBoard()
+	board.MakeMove(0, 0)
+	board.MakeMove(1, 1)
+	oToMove := board.Clone()
+	oToMove.MakeMove(2, 2)
+
+	tests := []struct {
+		encoder Encoder
+		board   *game.Board
+		want    map[int]float64
+	}{
+		{AbsoluteEncoder{}, oToMove, map[int]float64{0: 1, 4: -1, 8: 1}},
+		{PerspectiveEncoder{}, board, map[int]float64{0: 1, 4: -1}},
+		{PerspectiveEncoder{}, oToMove, map[int]float64{0: -1, 4: 1, 8: -1}},
+		// Planes: own, opponent, empty, side to move (X)
+		{PlaneEncoder{}, board, map[int]float64{0: 1, 9 + 4: 1, 18 + 1: 1, 18 + 0: 0, 27 + 5: 1}},
+		{PlaneEncoder{}, oToMove, map[int]float64{4: 1, 9 + 0: 1, 9 + 8: 1, 18 + 4: 0, 27 + 5: 0}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.encoder.Name(), func(t *testing.T) {
+			input := tt.encoder.Encode(tt.board)
+			if len(input) != tt.encoder.InputSize(9) {
+				t.Fatalf("Encode() has %d values, InputSize(9) = %d", len(input), tt.encoder.InputSize(9))
+			}
+			for i, want := range tt.want {
+				if input[i] != want {
+					t.Errorf("input[%d] = %v, want %v", i, input[i], want)
+				}
+			}
+		})
+	}
+
+	// The encoder is saved with the network and used for prediction after loading
+	network, err := NewMultiLayerNetwork([]int{36, 9}, []ActivationFunction{&Softmax{}})
+	if err != nil {
+		t.Fatalf("NewMultiLayerNetwork returned error: %v", err)
+	}
+	network.Encoder = PlaneEncoder{}
+	path := t.TempDir() + "/model.json"
+	if err := SaveModel(path, network, nil); err != nil {
+		t.Fatalf("SaveModel returned error: %v", err)
+	}
+	loaded, err := LoadNetwork(path)
+	if err != nil {
+		t.Fatalf("LoadNetwork returned error: %v", err)
+	}
+	if loaded.GetEncoder().Name() != "planes" {
+		t.Errorf("loaded encoder = %s, want planes", loaded.GetEncoder().Name())
+	}
+	if probabilities := PredictLegalMoveProbabilities(loaded, board); probabilities[0] != 0 || len(probabilities) != 9 {
+		t.Errorf("PredictLegalMoveProbabilities() = %v", probabilities)
+	}
+	if err := CheckNetworkFits(loaded, game.TicTacToe{}); err != nil {
+		t.Errorf("CheckNetworkFits() returned error: %v", err)
+	}
+	if err := CheckNetworkFits(NewNetwork(9, 9), game.TicTacToe{}); err != nil || NewNetwork(9, 9).GetEncoder().Name() != "absolute" {
+		t.Errorf("a network without an encoder should use the absolute encoding (%v)", err)
+	}
+	if _, err := NewEncoder("onehot"); err == nil {
+		t.Error("expected an error for an unknown encoder")
+	}
+
+	// Version 1 models predate encoders and are always absolute-encoded
+	var buf bytes.Buffer
+	if err := WriteModel(&buf, network, nil); err != nil {
+		t.Fatalf("WriteModel returned error: %v", err)
+	}
+	current := fmt.Sprintf(`"version": %d`, ModelFormatVersion)
+	if _, _, err := ReadModel(strings.NewReader(strings.Replace(buf.String(), current, `"version": 1`, 1))); err == nil {
+		t.Error("a version 1 model should not name an encoder")
+	}
+	buf.Reset()
+	if err := WriteModel(&buf, NewNetwork(9, 9), nil); err != nil {
+		t.Fatalf("WriteModel returned error: %v", err)
+	}
+	old, _, err := ReadModel(strings.NewReader(strings.Replace(buf.String(), current, `"version": 1`, 1)))
+	if err != nil || old.GetEncoder().Name() != "absolute" {
+		t.Errorf("version 1 model: %v", err)
+	}
+	if _, _, err := ReadModel(strings.NewReader(strings.Replace(buf.String(), current, `"version": 99`, 1))); err == nil {
+		t.Error("expected an error for a newer model format")
+	}
+}
+
+func TestPolicyValueNetwork(t *testing.T) {
+	utils.SetLogLevel(utils.ERROR)
+	SetRandomSeed(5)
+
+	network, err := NewPolicyValueNetwork([]int{9, 12, 8}, 9, &Tanh{})
+	if err != nil {
+		t.Fatalf("NewPolicyValueNetwork returned error: %v", err)
+	}
+	input := []float64{1, 0, -1, 0, 1, 0, 0, 0, -1}
+	targetPolicy := []float64{0, 0.5, 0, 0.25, 0, 0.25, 0, 0, 0}
+	const targetValue, l2 = 0.6, 1e-3
+
+	policy, value := network.Forward(input)
+	if len(policy) != 9 || value < -1 || value > 1 {
+		t.Fatalf("Forward() = %v, %v", policy, value)
+	}
+
+	// Compare the analytic gradients with finite differences of the loss
+	grads, _ := network.Gradients(input, targetPolicy, targetValue, l2)
+	const h = 1e-6
+	for l, layer := range network.GetLayers() {
+		neuron := layer.Neurons[0]
+		original := neuron.Weights[0]
+		neuron.Weights[0] = original + h
+		plus := network.Loss(input, targetPolicy, targetValue, l2).Total
+		neuron.Weights[0] = original - h
+		minus := network.Loss(input, targetPolicy, targetValue, l2).Total
+		neuron.Weights[0] = original
+
+		numeric := (plus - minus) / (2 * h)
+		if math.Abs(numeric-grads.Weights[l][0][0]) > 1e-5 {
+			t.Errorf("layer %d: gradient %v, numeric %v", l, grads.Weights[l][0][0], numeric)
+		}
+	}
+
+	// A few optimizer steps reduce the combined loss
+	optimizer := NewAdam(0.01)
+	before := network.Loss(input, targetPolicy, targetValue, l2).Total
+	for i := 0; i < 50; i++ {
+		grads, _ := network.Gradients(input, targetPolicy, targetValue, l2)
+		optimizer.Step(network.GetLayers(), grads)
+	}
+	after := network.Loss(input, targetPolicy, targetValue, l2)
+	if after.Total >= before || math.Abs(after.Total-after.Policy-after.Value-after.L2) > 1e-12 {
+		t.Errorf("loss went from %v to %+v", before, after)
+	}
+
+	// A clone predicts the same and is unaffected by training the original
+	clone := network.Clone()
+	clonePolicy, cloneValue := clone.Forward(input)
+	if _, value := network.Forward(input); cloneValue != value {
+		t.Errorf("clone value %v, want %v", cloneValue, value)
+	}
+	grads, _ = network.Gradients(input, targetPolicy, targetValue, l2)
+	optimizer.Step(network.GetLayers(), grads)
+	if policy, value := clone.Forward(input); value != cloneValue || policy[1] != clonePolicy[1] {
+		t.Error("training the original changed its clone")
+	}
+
+	// Both heads, the encoder and the optimizer survive a save and load
+	network.Encoder = PerspectiveEncoder{}
+	path := t.TempDir() + "/pv.json"
+	if err := SavePolicyValueModel(path, network, optimizer); err != nil {
+		t.Fatalf("SavePolicyValueModel returned error: %v", err)
+	}
+	loaded, loadedOptimizer, err := LoadPolicyValueModel(path)
+	if err != nil {
+		t.Fatalf("LoadPolicyValueModel returned error: %v", err)
+	}
+	wantPolicy, wantValue := network.Forward(input)
+	gotPolicy, gotValue := loaded.Forward(input)
+	if gotValue != wantValue || gotPolicy[1] != wantPolicy[1] || loadedOptimizer == nil {
+		t.Errorf("loaded network differs: value %v, want %v", gotValue, wantValue)
+	}
+	if loaded.GetEncoder().Name() != "perspective" || CheckNetworkFits(loaded, game.TicTacToe{}) != nil {
+		t.Errorf("loaded encoder %s does not fit tic-tac-toe", loaded.GetEncoder().Name())
+	}
+	if _, _, err := LoadModel(path); err == nil {
+		t.Error("LoadModel should reject a policy/value model")
+	}
+	if _, err := LoadNetwork(path); !errors.Is(err, ErrPolicyValueModel) {
+		t.Errorf("LoadNetwork error = %v, want ErrPolicyValueModel", err)
+	}
+
+	// Heads were added in version 2, so a version 1 file with heads is rejected
+	var buf bytes.Buffer
+	plain := loaded.Clone()
+	plain.Encoder = nil
+	if err := WritePolicyValueModel(&buf, plain, nil); err != nil {
+		t.Fatalf("WritePolicyValueModel returned error: %v", err)
+	}
+	v1 := strings.Replace(buf.String(), fmt.Sprintf(`"version": %d`, ModelFormatVersion), `"version": 1`, 1)
+	if _, _, err := ReadPolicyValueModel(strings.NewReader(v1)); err == nil {
+		t.Error("ReadPolicyValueModel should reject a version 1 model")
+	}
+
+	// PredictPolicyValue masks the occupied cells
+	board := game.NewBoard()
+	board.MakeMove(1, 1)
+	probabilities, _ := PredictPolicyValue(loaded, board)
+	if probabilities[4] != 0 {
+		t.Errorf("occupied cell has probability %v", probabilities[4])
+	}
+}
@@ -2,7 +2,6 @@ package neural
 
 import (
 	"math"
-	"math/rand"
 )
 
 // Neuron represents a single neuron in a neural network
@@ -15,6 +14,27 @@ type Neuron struct {
 
 	// Activation is the activation function used by the neuron
 	Activation ActivationFunction
+
+	// OptimizerState holds the optimizer's per-parameter state for this neuron
+	// It is created lazily by the first optimizer step
+	OptimizerState *OptimizerState
+}
+
+// OptimizerState holds per-parameter optimizer state for a neuron's weights and bias
+// Optimizers use the slots they need: momentum keeps a velocity in the first moment,
+// RMSProp and AdaGrad keep squared gradients in the second moment, and Adam uses both
+type OptimizerState struct {
+	// Moment is the first moment (velocity) for each weight
+	Moment []float64 `json:"moment"`
+
+	// SquareMoment is the second moment (accumulated squared gradient) for each weight
+	SquareMoment []float64 `json:"square_moment"`
+
+	// BiasMoment is the first moment for the bias
+	BiasMoment float64 `json:"bias_moment"`
+
+	// BiasSquareMoment is the second moment for the bias
+	BiasSquareMoment float64 `json:"bias_square_moment"`
 }
 
 // NewNeuron creates a new neuron with the specified number of inputs and activation function
@@ -43,11 +63,11 @@ func (n *Neuron) InitializeWeights() {
 
 	for i := range n.Weights {
 		// Initialize with random values scaled by the scale factor
-		n.Weights[i] = rand.NormFloat64() * scale
+		n.Weights[i] = rng.NormFloat64() * scale
 	}
 
 	// Initialize bias to a small random value
-	n.Bias = rand.NormFloat64() * 0.01
+	n.Bias = rng.NormFloat64() * 0.01
 }
 
 // Forward performs a forward pass through the neuron
@@ -58,14 +78,23 @@ func (n *Neuron) Forward(input []float64) float64 {
 		return 0.0
 	}
 
-	// Calculate weighted sum
+	// Apply activation function to the weighted sum
+	return n.Activation.Activate(n.WeightedSum(input))
+}
+
+// WeightedSum calculates the pre-activation value of the neuron
+// It is the dot product of the weights and the input plus the bias
+func (n *Neuron) WeightedSum(input []float64) float64 {
+	if len(input) != len(n.Weights) {
+		// Handle error: input size doesn't match weight size
+		return 0.0
+	}
+
 	sum := n.Bias
 	for i, weight := range n.Weights {
 		sum += weight * input[i]
 	}
-
-	// Apply activation function
-	return n.Activation.Activate(sum)
+	return sum
 }
 
 // GetWeights returns a copy of the neuron's weights
@@ -0,0 +1,237 @@
+package neural
+
+import (
+	"fmt"
+	"math"
+	"sort"
+)
+
+// Optimizer defines the interface for gradient-based optimization algorithms
+type Optimizer interface {
+	// Step updates the weights and biases of the layers using the given gradients
+	// grads must be shaped like layers, as returned by Network.Backward
+	Step(layers []*Layer, grads *Gradients)
+
+	// Name returns the name of the optimizer
+	Name() string
+}
+
+// optimizerConstructors maps optimizer names to constructors using default hyperparameters
+var optimizerConstructors = map[string]func(learningRate float64) Optimizer{
+	"sgd":      func(lr float64) Optimizer { return NewSGD(lr) },
+	"momentum": func(lr float64) Optimizer { return NewMomentum(lr, 0.9, false) },
+	"nesterov": func(lr float64) Optimizer { return NewMomentum(lr, 0.9, true) },
+	"rmsprop":  func(lr float64) Optimizer { return NewRMSProp(lr) },
+	"adagrad":  func(lr float64) Optimizer { return NewAdaGrad(lr) },
+	"adam":     func(lr float64) Optimizer { return NewAdam(lr) },
+}
+
+// NewOptimizer creates an optimizer by name with default hyperparameters
+// Supported names are returned by OptimizerNames
+func NewOptimizer(name string, learningRate float64) (Optimizer, error) {
+	constructor, ok := optimizerConstructors[name]
+	if !ok {
+		return nil, fmt.Errorf("unknown optimizer %q (available: %v)", name, OptimizerNames())
+	}
+	return constructor(learningRate), nil
+}
+
+// OptimizerNames returns the sorted names of all available optimizers
+func OptimizerNames() []string {
+	names := make([]string, 0, len(optimizerConstructors))
+	for name := range optimizerConstructors {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// optimizerState returns the neuron's optimizer state, creating it if needed
+func (n *Neuron) optimizerState() *OptimizerState {
+	if n.OptimizerState == nil || len(n.OptimizerState.Moment) != len(n.Weights) {
+		n.OptimizerState = &OptimizerState{
+			Moment:       make([]float64, len(n.Weights)),
+			SquareMoment: make([]float64, len(n.Weights)),
+		}
+	}
+	return n.OptimizerState
+}
+
+// applyUpdate walks every weight and bias in the layers and subtracts the step
+// computed by update, which receives the parameter's gradient and its moment slots
+func applyUpdate(layers []*Layer, grads *Gradients, update func(grad float64, moment, squareMoment *float64) float64) {
+	for l, layer := range layers {
+		for i, neuron := range layer.Neurons {
+			state := neuron.optimizerState()
+			for j := range neuron.Weights {
+				neuron.Weights[j] -= update(grads.Weights[l][i][j], &state.Moment[j], &state.SquareMoment[j])
+			}
+			neuron.Bias -= update(grads.Biases[l][i], &state.BiasMoment, &state.BiasSquareMoment)
+		}
+	}
+}
+
+// SGD implements plain stochastic gradient descent
+// param = param - learningRate * grad
+type SGD struct {
+	LearningRate float64
+}
+
+// NewSGD creates a new SGD optimizer
+func NewSGD(learningRate float64) *SGD {
+	return &SGD{LearningRate: learningRate}
+}
+
+// Step applies a gradient descent update
+func (o *SGD) Step(layers []*Layer, grads *Gradients) {
+	applyUpdate(layers, grads, func(grad float64, _, _ *float64) float64 {
+		return o.LearningRate * grad
+	})
+}
+
+// Name returns the name of the optimizer
+func (o *SGD) Name() string {
+	return "sgd"
+}
+
+// Momentum implements SGD with classical or Nesterov momentum
+// v = momentum * v + grad
+// param = param - learningRate * v                       (classical)
+// param = param - learningRate * (grad + momentum * v)   (Nesterov)
+type Momentum struct {
+	LearningRate float64
+	Momentum     float64
+	Nesterov     bool
+}
+
+// NewMomentum creates a new momentum optimizer
+func NewMomentum(learningRate, momentum float64, nesterov bool) *Momentum {
+	return &Momentum{
+		LearningRate: learningRate,
+		Momentum:     momentum,
+		Nesterov:     nesterov,
+	}
+}
+
+// Step applies a momentum update
+func (o *Momentum) Step(layers []*Layer, grads *Gradients) {
+	applyUpdate(layers, grads, func(grad float64, velocity, _ *float64) float64 {
+		*velocity = o.Momentum**velocity + grad
+		if o.Nesterov {
+			return o.LearningRate * (grad + o.Momentum**velocity)
+		}
+		return o.LearningRate * *velocity
+	})
+}
+
+// Name returns the name of the optimizer
+func (o *Momentum) Name() string {
+	if o.Nesterov {
+		return "nesterov"
+	}
+	return "momentum"
+}
+
+// RMSProp scales each update by a moving average of squared gradients
+// s = decay * s + (1 - decay) * grad^2
+// param = param - learningRate * grad / (sqrt(s) + epsilon)
+type RMSProp struct {
+	LearningRate float64
+	Decay        float64
+	Epsilon      float64
+}
+
+// NewRMSProp creates a new RMSProp optimizer with the usual decay of 0.9
+func NewRMSProp(learningRate float64) *RMSProp {
+	return &RMSProp{
+		LearningRate: learningRate,
+		Decay:        0.9,
+		Epsilon:      1e-8,
+	}
+}
+
+// Step applies an RMSProp update
+func (o *RMSProp) Step(layers []*Layer, grads *Gradients) {
+	applyUpdate(layers, grads, func(grad float64, _, square *float64) float64 {
+		*square = o.Decay**square + (1-o.Decay)*grad*grad
+		return o.LearningRate * grad / (math.Sqrt(*square) + o.Epsilon)
+	})
+}
+
+// Name returns the name of the optimizer
+func (o *RMSProp) Name() string {
+	return "rmsprop"
+}
+
+// AdaGrad scales each update by the sum of all past squared gradients
+// s = s + grad^2
+// param = param - learningRate * grad / (sqrt(s) + epsilon)
+type AdaGrad struct {
+	LearningRate float64
+	Epsilon      float64
+}
+
+// NewAdaGrad creates a new AdaGrad optimizer
+func NewAdaGrad(learningRate float64) *AdaGrad {
+	return &AdaGrad{
+		LearningRate: learningRate,
+		Epsilon:      1e-8,
+	}
+}
+
+// Step applies an AdaGrad update
+func (o *AdaGrad) Step(layers []*Layer, grads *Gradients) {
+	applyUpdate(layers, grads, func(grad float64, _, square *float64) float64 {
+		*square += grad * grad
+		return o.LearningRate * grad / (math.Sqrt(*square) + o.Epsilon)
+	})
+}
+
+// Name returns the name of the optimizer
+func (o *AdaGrad) Name() string {
+	return "adagrad"
+}
+
+// Adam combines momentum with RMSProp-style scaling and bias correction
+// m = beta1 * m + (1 - beta1) * grad
+// v = beta2 * v + (1 - beta2) * grad^2
+// param = param - learningRate * m_hat / (sqrt(v_hat) + epsilon)
+type Adam struct {
+	LearningRate float64
+	Beta1        float64
+	Beta2        float64
+	Epsilon      float64
+
+	// StepCount is the number of updates applied so far, used for bias correction
+	StepCount int
+}
+
+// NewAdam creates a new Adam optimizer with the standard hyperparameters
+func NewAdam(learningRate float64) *Adam {
+	return &Adam{
+		LearningRate: learningRate,
+		Beta1:        0.9,
+		Beta2:        0.999,
+		Epsilon:      1e-8,
+	}
+}
+
+// Step applies an Adam update
+func (o *Adam) Step(layers []*Layer, grads *Gradients) {
+	o.StepCount++
+	correction1 := 1 - math.Pow(o.Beta1, float64(o.StepCount))
+	correction2 := 1 - math.Pow(o.Beta2, float64(o.StepCount))
+
+	applyUpdate(layers, grads, func(grad float64, m, v *float64) float64 {
+		*m = o.Beta1**m + (1-o.Beta1)*grad
+		*v = o.Beta2**v + (1-o.Beta2)*grad*grad
+		mHat := *m / correction1
+		vHat := *v / correction2
+		return o.LearningRate * mHat / (math.Sqrt(vHat) + o.Epsilon)
+	})
+}
+
+// Name returns the name of the optimizer
+func (o *Adam) Name() string {
+	return "adam"
+}
@@ -0,0 +1,449 @@
+package neural
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+)
+
+// ModelFormatVersion is the version of the model file format written by WriteModel
+// It is bumped whenever the format changes in a way older readers cannot handle.
+// Version 2 added the input encoder and the heads of policy/value networks;
+// version 1 models are read as absolute-encoded single-headed networks.
+const ModelFormatVersion = 2
+
+// ErrPolicyValueModel is returned by ReadModel for a policy/value model, which
+// ReadPolicyValueModel loads instead
+var ErrPolicyValueModel = errors.New("model is a policy/value network; load it with ReadPolicyValueModel")
+
+// modelFile is the on-disk representation of a network and its optimizer
+type modelFile struct {
+	Version   int            `json:"version"`
+	Topology  []int          `json:"topology"`
+	Encoder   string         `json:"encoder,omitempty"` // empty for the absolute encoder
+	Layers    []layerFile    `json:"layers"`
+	Optimizer *optimizerFile `json:"optimizer,omitempty"`
+
+	// PolicyHead and ValueHead are only set for a PolicyValueNetwork,
+	// whose trunk is stored in Topology and Layers
+	PolicyHead *headFile `json:"policy_head,omitempty"`
+	ValueHead  *headFile `json:"value_head,omitempty"`
+}
+
+// headFile is the on-disk representation of one head of a PolicyValueNetwork
+type headFile struct {
+	Topology []int       `json:"topology"`
+	Layers   []layerFile `json:"layers"`
+}
+
+// layerFile is the on-disk representation of a layer
+type layerFile struct {
+	Activation string       `json:"activation"`
+	Alpha      float64      `json:"alpha,omitempty"`
+	Neurons    []neuronFile `json:"neurons"`
+}
+
+// neuronFile is the on-disk representation of a neuron
+type neuronFile struct {
+	Weights []float64       `json:"weights"`
+	Bias    float64         `json:"bias"`
+	State   *OptimizerState `json:"state,omitempty"`
+}
+
+// optimizerFile is the on-disk representation of an optimizer's hyperparameters
+// The per-parameter state is stored with each neuron
+type optimizerFile struct {
+	Name         string  `json:"name"`
+	LearningRate float64 `json:"learning_rate"`
+	Momentum     float64 `json:"momentum,omitempty"`
+	Decay        float64 `json:"decay,omitempty"`
+	Beta1        float64 `json:"beta1,omitempty"`
+	Beta2        float64 `json:"beta2,omitempty"`
+	Epsilon      float64 `json:"epsilon,omitempty"`
+	StepCount    int     `json:"step_count,omitempty"`
+}
+
+// WriteModel serializes a network and, optionally, its optimizer to w
+// optimizer may be nil when only the network is needed for inference
+func WriteModel(w io.Writer, network *Network, optimizer Optimizer) error {
+	file, err := encodeModel(network, optimizer)
+	if err != nil {
+		return err
+	}
+
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+	if err := encoder.Encode(file); err != nil {
+		return fmt.Errorf("failed to encode model: %w", err)
+	}
+	return nil
+}
+
+// ReadModel deserializes a network and its optimizer from r
+// The returned optimizer is nil if none was saved with the network.
+// Policy/value models are rejected rather than loaded as their trunk alone.
+func ReadModel(r io.Reader) (*Network, Optimizer, error) {
+	var file modelFile
+	if err := json.NewDecoder(r).Decode(&file); err != nil {
+		return nil, nil, fmt.Errorf("failed to decode model: %w", err)
+	}
+	if file.PolicyHead != nil || file.ValueHead != nil {
+		return nil, nil, ErrPolicyValueModel
+	}
+	return decodeModel(&file)
+}
+
+// WritePolicyValueModel serializes a policy/value network and, optionally, its optimizer to w
+func WritePolicyValueModel(w io.Writer, network *PolicyValueNetwork, optimizer Optimizer) error {
+	file, err := encodeModel(network.Trunk, optimizer)
+	if err != nil {
+		return err
+	}
+	file.Encoder = ""
+	if encoder := network.GetEncoder(); encoder.Name() != (AbsoluteEncoder{}).Name() {
+		file.Encoder = encoder.Name()
+	}
+	if file.PolicyHead, err = encodeHead(network.Policy); err != nil {
+		return fmt.Errorf("policy head: %w", err)
+	}
+	if file.ValueHead, err = encodeHead(network.Value); err != nil {
+		return fmt.Errorf("value head: %w", err)
+	}
+
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+	if err := encoder.Encode(file); err != nil {
+		return fmt.Errorf("failed to encode model: %w", err)
+	}
+	return nil
+}
+
+// ReadPolicyValueModel deserializes a policy/value network and its optimizer from r
+// The returned optimizer is nil if none was saved with the network
+func ReadPolicyValueModel(r io.Reader) (*PolicyValueNetwork, Optimizer, error) {
+	var file modelFile
+	if err := json.NewDecoder(r).Decode(&file); err != nil {
+		return nil, nil, fmt.Errorf("failed to decode model: %w", err)
+	}
+	if file.PolicyHead == nil || file.ValueHead == nil {
+		return nil, nil, fmt.Errorf("model has no policy and value heads; load it with ReadModel")
+	}
+
+	trunk, optimizer, err := decodeModel(&file)
+	if err != nil {
+		return nil, nil, err
+	}
+	policy, err := decodeHead(file.PolicyHead)
+	if err != nil {
+		return nil, nil, fmt.Errorf("policy head: %w", err)
+	}
+	value, err := decodeHead(file.ValueHead)
+	if err != nil {
+		return nil, nil, fmt.Errorf("value head: %w", err)
+	}
+	if policy.GetInputSize() != trunk.GetOutputSize() || value.GetInputSize() != trunk.GetOutputSize() {
+		return nil, nil, fmt.Errorf("heads do not match the trunk's %d outputs", trunk.GetOutputSize())
+	}
+
+	network := &PolicyValueNetwork{
+		Trunk:   trunk,
+		Policy:  policy,
+		Value:   value,
+		Encoder: trunk.Encoder,
+	}
+	trunk.Encoder = nil
+	return network, optimizer, nil
+}
+
+// SaveModel writes a network and its optimizer to the file at path
+// The file is written to a temporary location first and then renamed,
+// so an interrupted save never leaves a truncated model behind
+func SaveModel(path string, network *Network, optimizer Optimizer) error {
+	return saveFile(path, func(w io.Writer) error {
+		return WriteModel(w, network, optimizer)
+	})
+}
+
+// SavePolicyValueModel writes a policy/value network and its optimizer to the file at path
+// Like SaveModel, it never leaves a truncated model behind
+func SavePolicyValueModel(path string, network *PolicyValueNetwork, optimizer Optimizer) error {
+	return saveFile(path, func(w io.Writer) error {
+		return WritePolicyValueModel(w, network, optimizer)
+	})
+}
+
+// LoadPolicyValueModel reads a policy/value network and its optimizer from the file at path
+func LoadPolicyValueModel(path string) (*PolicyValueNetwork, Optimizer, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to open model file: %w", err)
+	}
+	defer f.Close()
+
+	return ReadPolicyValueModel(f)
+}
+
+// saveFile writes a model to a temporary file next to path and renames it into place
+func saveFile(path string, write func(w io.Writer) error) error {
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return fmt.Errorf("failed to create model directory: %w", err)
+	}
+
+	tmp, err := os.CreateTemp(filepath.Dir(path), filepath.Base(path)+".tmp*")
+	if err != nil {
+		return fmt.Errorf("failed to create model file: %w", err)
+	}
+	defer os.Remove(tmp.Name())
+
+	if err := write(tmp); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return fmt.Errorf("failed to write model file: %w", err)
+	}
+	if err := os.Rename(tmp.Name(), path); err != nil {
+		return fmt.Errorf("failed to move model file into place: %w", err)
+	}
+	return nil
+}
+
+// LoadModel reads a network and its optimizer from the file at path
+func LoadModel(path string) (*Network, Optimizer, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to open model file: %w", err)
+	}
+	defer f.Close()
+
+	return ReadModel(f)
+}
+
+// LoadNetwork reads only the network from the file at path, ignoring any optimizer state
+func LoadNetwork(path string) (*Network, error) {
+	network, _, err := LoadModel(path)
+	return network, err
+}
+
+// encodeModel converts a network and optimizer into their on-disk representation
+func encodeModel(network *Network, optimizer Optimizer) (*modelFile, error) {
+	layers := network.GetLayers()
+	if len(layers) == 0 {
+		return nil, fmt.Errorf("network has no layers")
+	}
+
+	file := &modelFile{
+		Version:  ModelFormatVersion,
+		Topology: []int{len(layers[0].Neurons[0].Weights)},
+		Layers:   make([]layerFile, len(layers)),
+	}
+
+	if encoder := network.GetEncoder(); encoder.Name() != (AbsoluteEncoder{}).Name() {
+		file.Encoder = encoder.Name()
+	}
+
+	for l, layer := range layers {
+		file.Topology = append(file.Topology, len(layer.Neurons))
+		file.Layers[l] = encodeLayer(layer)
+	}
+
+	if optimizer != nil {
+		opt, err := encodeOptimizer(optimizer)
+		if err != nil {
+			return nil, err
+		}
+		file.Optimizer = opt
+	}
+
+	return file, nil
+}
+
+// encodeHead converts one head of a policy/value network into its on-disk representation
+func encodeHead(network *Network) (*headFile, error) {
+	file, err := encodeModel(network, nil)
+	if err != nil {
+		return nil, err
+	}
+	return &headFile{Topology: file.Topology, Layers: file.Layers}, nil
+}
+
+// decodeHead rebuilds one head of a policy/value network
+func decodeHead(head *headFile) (*Network, error) {
+	network, _, err := decodeModel(&modelFile{
+		Version:  ModelFormatVersion,
+		Topology: head.Topology,
+		Layers:   head.Layers,
+	})
+	return network, err
+}
+
+// encodeLayer converts a layer into its on-disk representation
+func encodeLayer(layer *Layer) layerFile {
+	activation := layer.Neurons[0].Activation
+	lf := layerFile{
+		Activation: activation.Name(),
+		Neurons:    make([]neuronFile, len(layer.Neurons)),
+	}
+
+	switch a := activation.(type) {
+	case *LeakyReLU:
+		lf.Alpha = a.Alpha
+	case *ELU:
+		lf.Alpha = a.Alpha
+	}
+
+	for n, neuron := range layer.Neurons {
+		lf.Neurons[n] = neuronFile{
+			Weights: neuron.GetWeights(),
+			Bias:    neuron.Bias,
+			State:   neuron.OptimizerState,
+		}
+	}
+
+	return lf
+}
+
+// decodeModel rebuilds a network and optimizer from their on-disk representation
+func decodeModel(file *modelFile) (*Network, Optimizer, error) {
+	if file.Version < 1 || file.Version > ModelFormatVersion {
+		return nil, nil, fmt.Errorf("unsupported model format version %d (expected %d or older)", file.Version, ModelFormatVersion)
+	}
+	if file.Version == 1 && file.Encoder != "" {
+		return nil, nil, fmt.Errorf("version 1 models use the absolute encoder, not %s", file.Encoder)
+	}
+	if file.Version == 1 && (file.PolicyHead != nil || file.ValueHead != nil) {
+		return nil, nil, fmt.Errorf("version 1 models cannot have policy and value heads")
+	}
+	if len(file.Layers) == 0 || len(file.Topology) != len(file.Layers)+1 {
+		return nil, nil, fmt.Errorf("model topology %v does not match %d layers", file.Topology, len(file.Layers))
+	}
+
+	network := &Network{
+		Layers: make([]*Layer, len(file.Layers)),
+	}
+	for l, lf := range file.Layers {
+		layer, err := decodeLayer(lf, file.Topology[l], file.Topology[l+1])
+		if err != nil {
+			return nil, nil, fmt.Errorf("layer %d: %w", l, err)
+		}
+		network.Layers[l] = layer
+	}
+	network.OutputLayer = network.Layers[len(network.Layers)-1]
+
+	if file.Encoder != "" {
+		encoder, err := NewEncoder(file.Encoder)
+		if err != nil {
+			return nil, nil, err
+		}
+		network.Encoder = encoder
+	}
+
+	var optimizer Optimizer
+	if file.Optimizer != nil {
+		opt, err := decodeOptimizer(file.Optimizer)
+		if err != nil {
+			return nil, nil, err
+		}
+		optimizer = opt
+	}
+
+	return network, optimizer, nil
+}
+
+// decodeLayer rebuilds a layer and checks it against the expected topology
+func decodeLayer(lf layerFile, inputSize, neuronCount int) (*Layer, error) {
+	if len(lf.Neurons) != neuronCount {
+		return nil, fmt.Errorf("expected %d neurons, got %d", neuronCount, len(lf.Neurons))
+	}
+
+	layer := &Layer{
+		Neurons: make([]*Neuron, neuronCount),
+		Output:  make([]float64, neuronCount),
+		Sums:    make([]float64, neuronCount),
+	}
+
+	// All neurons in a layer share one activation function, as in NewLayer
+	activation, err := NewActivation(lf.Activation)
+	if err != nil {
+		return nil, err
+	}
+	switch a := activation.(type) {
+	case *LeakyReLU:
+		a.Alpha = lf.Alpha
+	case *ELU:
+		a.Alpha = lf.Alpha
+	}
+
+	for n, nf := range lf.Neurons {
+		if len(nf.Weights) != inputSize {
+			return nil, fmt.Errorf("neuron %d: expected %d weights, got %d", n, inputSize, len(nf.Weights))
+		}
+
+		layer.Neurons[n] = &Neuron{
+			Weights:        nf.Weights,
+			Bias:           nf.Bias,
+			Activation:     activation,
+			OptimizerState: nf.State,
+		}
+	}
+
+	return layer, nil
+}
+
+// encodeOptimizer converts an optimizer's hyperparameters into their on-disk representation
+func encodeOptimizer(optimizer Optimizer) (*optimizerFile, error) {
+	file := &optimizerFile{Name: optimizer.Name()}
+
+	switch o := optimizer.(type) {
+	case *SGD:
+		file.LearningRate = o.LearningRate
+	case *Momentum:
+		file.LearningRate = o.LearningRate
+		file.Momentum = o.Momentum
+	case *RMSProp:
+		file.LearningRate = o.LearningRate
+		file.Decay = o.Decay
+		file.Epsilon = o.Epsilon
+	case *AdaGrad:
+		file.LearningRate = o.LearningRate
+		file.Epsilon = o.Epsilon
+	case *Adam:
+		file.LearningRate = o.LearningRate
+		file.Beta1 = o.Beta1
+		file.Beta2 = o.Beta2
+		file.Epsilon = o.Epsilon
+		file.StepCount = o.StepCount
+	default:
+		return nil, fmt.Errorf("cannot save optimizer of type %T", optimizer)
+	}
+
+	return file, nil
+}
+
+// decodeOptimizer rebuilds an optimizer from its on-disk representation
+func decodeOptimizer(file *optimizerFile) (Optimizer, error) {
+	optimizer, err := NewOptimizer(file.Name, file.LearningRate)
+	if err != nil {
+		return nil, err
+	}
+
+	switch o := optimizer.(type) {
+	case *Momentum:
+		o.Momentum = file.Momentum
+	case *RMSProp:
+		o.Decay = file.Decay
+		o.Epsilon = file.Epsilon
+	case *AdaGrad:
+		o.Epsilon = file.Epsilon
+	case *Adam:
+		o.Beta1 = file.Beta1
+		o.Beta2 = file.Beta2
+		o.Epsilon = file.Epsilon
+		o.StepCount = file.StepCount
+	}
+
+	return optimizer, nil
+}
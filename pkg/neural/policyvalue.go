@@ -0,0 +1,194 @@
+package neural
+
+import (
+	"fmt"
+
+	"github.com/ZachBeta/go_neural_network_learning/pkg/game"
+)
+
+// PolicyValueNetwork is a network with a shared trunk and two heads, as used by AlphaZero:
+// the policy head gives a probability for every move and the value head estimates
+// the outcome of the position for the player to move, from -1 (loss) to 1 (win)
+type PolicyValueNetwork struct {
+	// Trunk holds the hidden layers shared by both heads
+	Trunk *Network
+
+	// Policy is the policy head, a softmax layer over the moves
+	Policy *Network
+
+	// Value is the value head, a single tanh neuron
+	Value *Network
+
+	// Encoder converts boards into the input the network was trained on
+	// A nil Encoder means AbsoluteEncoder
+	Encoder Encoder
+}
+
+// PolicyValueLoss holds the parts of the combined policy/value loss
+type PolicyValueLoss struct {
+	Policy float64 // cross-entropy between the target and predicted move probabilities
+	Value  float64 // squared error between the target and predicted value
+	L2     float64 // weight decay penalty
+	Total  float64
+}
+
+// NewPolicyValueNetwork creates a policy/value network
+// layerSizes lists the input size followed by the size of each trunk layer, e.g.
+// []int{9, 64, 32}; activation is used for every trunk layer and defaults to ReLU when nil.
+// The policy head has actionSize outputs.
+func NewPolicyValueNetwork(layerSizes []int, actionSize int, activation ActivationFunction) (*PolicyValueNetwork, error) {
+	if len(layerSizes) < 2 {
+		return nil, fmt.Errorf("policy/value network needs an input size and at least one trunk layer, got %d sizes", len(layerSizes))
+	}
+	if activation == nil {
+		activation = &ReLU{}
+	}
+
+	activations := make([]ActivationFunction, len(layerSizes)-1)
+	for i := range activations {
+		activations[i] = activation
+	}
+	trunk, err := NewMultiLayerNetwork(layerSizes, activations)
+	if err != nil {
+		return nil, fmt.Errorf("trunk: %w", err)
+	}
+
+	features := layerSizes[len(layerSizes)-1]
+	policy, err := NewMultiLayerNetwork([]int{features, actionSize}, []ActivationFunction{&Softmax{}})
+	if err != nil {
+		return nil, fmt.Errorf("policy head: %w", err)
+	}
+	value, err := NewMultiLayerNetwork([]int{features, 1}, []ActivationFunction{&Tanh{}})
+	if err != nil {
+		return nil, fmt.Errorf("value head: %w", err)
+	}
+
+	return &PolicyValueNetwork{Trunk: trunk, Policy: policy, Value: value}, nil
+}
+
+// Forward performs a forward pass and returns the move probabilities and the value
+// It is the two-headed counterpart of Network.Forward
+func (p *PolicyValueNetwork) Forward(input []float64) (policy []float64, value float64) {
+	features := p.Trunk.Forward(input)
+	policy = append([]float64(nil), p.Policy.Forward(features)...)
+	value = p.Value.Forward(features)[0]
+	return policy, value
+}
+
+// Backward performs a backward pass after Forward
+// policyGrad is the gradient of the loss with respect to the policy output and
+// valueGrad the gradient with respect to the value. The returned gradients
+// cover the layers returned by GetLayers, in the same order.
+func (p *PolicyValueNetwork) Backward(policyGrad []float64, valueGrad float64) *Gradients {
+	policyInputGrad, policyGrads := backward(p.Policy.GetLayers(), policyGrad)
+	valueInputGrad, valueGrads := backward(p.Value.GetLayers(), []float64{valueGrad})
+
+	// The trunk receives the sum of the gradients flowing back from both heads
+	featureGrad := make([]float64, len(policyInputGrad))
+	for i := range featureGrad {
+		featureGrad[i] = policyInputGrad[i] + valueInputGrad[i]
+	}
+	_, trunkGrads := backward(p.Trunk.GetLayers(), featureGrad)
+
+	return &Gradients{
+		Weights: append(append(trunkGrads.Weights, policyGrads.Weights...), valueGrads.Weights...),
+		Biases:  append(append(trunkGrads.Biases, policyGrads.Biases...), valueGrads.Biases...),
+	}
+}
+
+// Clone returns a copy of the network with its own weights and no optimizer state
+func (p *PolicyValueNetwork) Clone() *PolicyValueNetwork {
+	return &PolicyValueNetwork{
+		Trunk:   p.Trunk.Clone(),
+		Policy:  p.Policy.Clone(),
+		Value:   p.Value.Clone(),
+		Encoder: p.Encoder,
+	}
+}
+
+// GetLayers returns the trunk, policy head and value head layers, in that order
+// Optimizers step these layers with the gradients returned by Backward.
+func (p *PolicyValueNetwork) GetLayers() []*Layer {
+	layers := append([]*Layer(nil), p.Trunk.GetLayers()...)
+	layers = append(layers, p.Policy.GetLayers()...)
+	return append(layers, p.Value.GetLayers()...)
+}
+
+// GetInputSize returns the number of inputs the network expects
+func (p *PolicyValueNetwork) GetInputSize() int {
+	return p.Trunk.GetInputSize()
+}
+
+// GetOutputSize returns the number of moves the policy head covers
+func (p *PolicyValueNetwork) GetOutputSize() int {
+	return p.Policy.GetOutputSize()
+}
+
+// GetEncoder returns the encoder for the network's input, AbsoluteEncoder if none was set
+func (p *PolicyValueNetwork) GetEncoder() Encoder {
+	if p.Encoder == nil {
+		return AbsoluteEncoder{}
+	}
+	return p.Encoder
+}
+
+// Loss computes the combined loss for one position without changing the network:
+// cross-entropy between targetPolicy and the policy, squared error between
+// targetValue and the value, and l2 times the sum of the squared weights
+func (p *PolicyValueNetwork) Loss(input, targetPolicy []float64, targetValue, l2 float64) PolicyValueLoss {
+	policy, value := p.Forward(input)
+	return p.loss(policy, value, targetPolicy, targetValue, l2)
+}
+
+// Gradients runs the network on one position and returns the gradients of the
+// combined loss (see Loss) along with the loss itself
+// Average the gradients over a batch and pass them to an optimizer with GetLayers.
+func (p *PolicyValueNetwork) Gradients(input, targetPolicy []float64, targetValue, l2 float64) (*Gradients, PolicyValueLoss) {
+	policy, value := p.Forward(input)
+	loss := p.loss(policy, value, targetPolicy, targetValue, l2)
+
+	// d/dv (z - v)^2 = 2 (v - z)
+	grads := p.Backward(CrossEntropyGradient(policy, targetPolicy), 2*(value-targetValue))
+
+	// d/dw l2 * w^2 = 2 * l2 * w; biases are not decayed
+	if l2 > 0 {
+		for l, layer := range p.GetLayers() {
+			for n, neuron := range layer.Neurons {
+				for w, weight := range neuron.Weights {
+					grads.Weights[l][n][w] += 2 * l2 * weight
+				}
+			}
+		}
+	}
+
+	return grads, loss
+}
+
+// loss combines the policy, value and weight decay terms
+func (p *PolicyValueNetwork) loss(policy []float64, value float64, targetPolicy []float64, targetValue, l2 float64) PolicyValueLoss {
+	loss := PolicyValueLoss{
+		Policy: CalculateCrossEntropy(policy, targetPolicy),
+		Value:  (targetValue - value) * (targetValue - value),
+	}
+	if l2 > 0 {
+		sum := 0.0
+		for _, layer := range p.GetLayers() {
+			for _, neuron := range layer.Neurons {
+				for _, weight := range neuron.Weights {
+					sum += weight * weight
+				}
+			}
+		}
+		loss.L2 = l2 * sum
+	}
+	loss.Total = loss.Policy + loss.Value + loss.L2
+	return loss
+}
+
+// PredictPolicyValue runs the network on a game state and returns move probabilities
+// restricted to the state's legal moves, along with the value of the position
+// for the player to move
+func PredictPolicyValue(network *PolicyValueNetwork, state game.State) ([]float64, float64) {
+	policy, value := network.Forward(EncodeState(network, state))
+	return MaskProbabilities(policy, StateMoveMask(state)), value
+}
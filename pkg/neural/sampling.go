@@ -0,0 +1,163 @@
+package neural
+
+import (
+	"math"
+	"sort"
+)
+
+// RandomSource is the part of a random number generator needed for sampling
+// Both *math/rand.Rand and *math/rand/v2.Rand satisfy it
+type RandomSource interface {
+	Float64() float64
+}
+
+// SamplingOptions controls how SampleMove picks a move from move probabilities
+type SamplingOptions struct {
+	// Temperature reshapes the distribution before sampling.
+	// 1 samples from the probabilities as they are, values below 1 sharpen
+	// the distribution towards the best move, values above 1 flatten it,
+	// and 0 always picks the most likely move.
+	Temperature float64
+
+	// TopK keeps only the K most likely moves (0 keeps all of them)
+	TopK int
+
+	// TopP keeps the smallest set of most likely moves whose probabilities
+	// add up to at least TopP (0 or 1 keeps all of them)
+	TopP float64
+}
+
+// TemperatureSchedule returns the sampling temperature for a move number (starting at 0)
+type TemperatureSchedule func(moveNum int) float64
+
+// ConstantTemperature returns a schedule that always uses the same temperature
+func ConstantTemperature(temperature float64) TemperatureSchedule {
+	return func(int) float64 {
+		return temperature
+	}
+}
+
+// StepTemperature returns a schedule that uses the initial temperature for the
+// first moves and the final temperature from move number switchMove onwards
+// AlphaZero uses a temperature of 1 for the opening moves and close to 0 afterwards,
+// which explores varied openings while still playing the rest of the game well
+func StepTemperature(initial, final float64, switchMove int) TemperatureSchedule {
+	return func(moveNum int) float64 {
+		if moveNum < switchMove {
+			return initial
+		}
+		return final
+	}
+}
+
+// ApplyTemperature reshapes probabilities by raising them to the power 1/temperature
+// and renormalizing. A temperature of 0 puts all the mass on the most likely move.
+// Moves with probability 0 (such as illegal moves) stay at 0.
+func ApplyTemperature(probabilities []float64, temperature float64) []float64 {
+	result := make([]float64, len(probabilities))
+
+	if temperature <= 0 {
+		best := SelectBestMove(probabilities)
+		if probabilities[best] > 0 {
+			result[best] = 1.0
+		}
+		return result
+	}
+
+	// Work in log space so small temperatures don't underflow
+	maxLog := math.Inf(-1)
+	for _, prob := range probabilities {
+		if prob > 0 {
+			maxLog = math.Max(maxLog, math.Log(prob)/temperature)
+		}
+	}
+
+	for i, prob := range probabilities {
+		if prob > 0 {
+			result[i] = math.Exp(math.Log(prob)/temperature - maxLog)
+		}
+	}
+
+	return normalize(result)
+}
+
+// TruncateProbabilities applies top-k and top-p (nucleus) truncation and renormalizes
+// Moves outside the kept set get probability 0
+func TruncateProbabilities(probabilities []float64, topK int, topP float64) []float64 {
+	// Order moves from most to least likely
+	order := make([]int, 0, len(probabilities))
+	for i, prob := range probabilities {
+		if prob > 0 {
+			order = append(order, i)
+		}
+	}
+	sort.SliceStable(order, func(a, b int) bool {
+		return probabilities[order[a]] > probabilities[order[b]]
+	})
+
+	keep := len(order)
+	if topK > 0 && topK < keep {
+		keep = topK
+	}
+
+	if topP > 0 && topP < 1 {
+		total := 0.0
+		for _, i := range order {
+			total += probabilities[i]
+		}
+		cumulative := 0.0
+		for n, i := range order[:keep] {
+			cumulative += probabilities[i]
+			if cumulative >= topP*total {
+				keep = n + 1
+				break
+			}
+		}
+	}
+
+	result := make([]float64, len(probabilities))
+	for _, i := range order[:keep] {
+		result[i] = probabilities[i]
+	}
+
+	return normalize(result)
+}
+
+// SampleMove draws a move index from the probabilities after applying the
+// temperature and truncation options. It returns -1 if no move has any probability.
+func SampleMove(probabilities []float64, opts SamplingOptions, rng RandomSource) int {
+	shaped := ApplyTemperature(probabilities, opts.Temperature)
+	shaped = TruncateProbabilities(shaped, opts.TopK, opts.TopP)
+
+	r := rng.Float64()
+	cumulative := 0.0
+	last := -1
+	for i, prob := range shaped {
+		if prob <= 0 {
+			continue
+		}
+		cumulative += prob
+		last = i
+		if r < cumulative {
+			return i
+		}
+	}
+
+	// Rounding can leave the cumulative sum just below 1
+	return last
+}
+
+// normalize scales the values in place to sum to 1 and returns them
+// All-zero input is returned unchanged
+func normalize(values []float64) []float64 {
+	sum := 0.0
+	for _, val := range values {
+		sum += val
+	}
+	if sum > 0 {
+		for i := range values {
+			values[i] /= sum
+		}
+	}
+	return values
+}
@@ -3,13 +3,18 @@ package neural
 import (
 	"math"
 	"math/rand"
+	"time"
 
 	"github.com/ZachBeta/go_neural_network_learning/pkg/logger"
 )
 
+// rng is the random number generator used for weight initialization
+// It has its own source because the global math/rand source can no longer be seeded
+var rng = rand.New(rand.NewSource(time.Now().UnixNano()))
+
 // SetRandomSeed sets the random seed for reproducibility
 func SetRandomSeed(seed int64) {
-	rand.Seed(seed)
+	rng = rand.New(rand.NewSource(seed))
 }
 
 // PrintWeights prints the weights of a neuron
@@ -36,8 +41,15 @@ func PrintLayer(layer *Layer) {
 // PrintNetwork prints the structure of a network
 func PrintNetwork(network *Network) {
 	logger.Info("Network Structure:")
-	logger.Info("Output Layer: %d neurons", network.OutputLayer.GetNeuronCount())
-	PrintLayer(network.OutputLayer)
+	layers := network.GetLayers()
+	for i, layer := range layers {
+		if i == len(layers)-1 {
+			logger.Info("Output Layer: %d neurons", layer.GetNeuronCount())
+		} else {
+			logger.Info("Hidden Layer %d: %d neurons", i+1, layer.GetNeuronCount())
+		}
+		PrintLayer(layer)
+	}
 }
 
 // PrintOutput prints the output of a layer
@@ -76,6 +88,21 @@ func CalculateMSE(predicted, target []float64) float64 {
 	return sum / float64(len(predicted))
 }
 
+// MSEGradient calculates the gradient of the mean squared error with respect to the predicted values
+// It returns nil if the slices have different lengths
+func MSEGradient(predicted, target []float64) []float64 {
+	if len(predicted) != len(target) {
+		return nil
+	}
+
+	grad := make([]float64, len(predicted))
+	for i, pred := range predicted {
+		grad[i] = 2.0 * (pred - target[i]) / float64(len(predicted))
+	}
+
+	return grad
+}
+
 // CalculateCrossEntropy calculates the cross-entropy loss between predicted and target values
 func CalculateCrossEntropy(predicted, target []float64) float64 {
 	if len(predicted) != len(target) {
@@ -97,3 +124,22 @@ func CalculateCrossEntropy(predicted, target []float64) float64 {
 
 	return -sum
 }
+
+// CrossEntropyGradient calculates the gradient of the cross-entropy loss with respect to the predicted values
+// It returns nil if the slices have different lengths
+func CrossEntropyGradient(predicted, target []float64) []float64 {
+	if len(predicted) != len(target) {
+		return nil
+	}
+
+	grad := make([]float64, len(predicted))
+	for i, pred := range predicted {
+		// Avoid division by zero
+		if pred < 1e-10 {
+			pred = 1e-10
+		}
+		grad[i] = -target[i] / pred
+	}
+
+	return grad
+}
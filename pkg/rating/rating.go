@@ -0,0 +1,263 @@
+package rating
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"os"
+	"path/filepath"
+	"sort"
+)
+
+const (
+	// DefaultRating is the rating given to players the first time they are seen
+	DefaultRating = 1500.0
+
+	// DefaultK is the default Elo K-factor, the maximum rating change per game
+	DefaultK = 32.0
+)
+
+// Entry holds a player's rating and game counts
+type Entry struct {
+	Name   string  `json:"name"`
+	Path   string  `json:"path,omitempty"` // file the player was last loaded from, shown with the name
+	Rating float64 `json:"rating"`
+	Games  int     `json:"games"`
+	Wins   int     `json:"wins"`
+	Draws  int     `json:"draws"`
+	Losses int     `json:"losses"`
+
+	// Opponents counts the games played against each opponent, for Swiss pairings
+	Opponents map[string]int `json:"opponents,omitempty"`
+}
+
+// Ladder keeps Elo ratings for a set of players
+type Ladder struct {
+	// Game is the name of the game the ratings are for, empty until one is set
+	Game    string            `json:"game,omitempty"`
+	K       float64           `json:"k"`
+	Entries map[string]*Entry `json:"entries"`
+}
+
+// NewLadder creates an empty ladder with the given K-factor
+func NewLadder(k float64) *Ladder {
+	return &Ladder{
+		K:       k,
+		Entries: make(map[string]*Entry),
+	}
+}
+
+// LoadLadder reads a ladder from a JSON file
+// A missing file yields a new, empty ladder using DefaultK
+func LoadLadder(path string) (*Ladder, error) {
+	data, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return NewLadder(DefaultK), nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read ratings: %w", err)
+	}
+
+	ladder := NewLadder(DefaultK)
+	if err := json.Unmarshal(data, ladder); err != nil {
+		return nil, fmt.Errorf("failed to decode ratings: %w", err)
+	}
+	if ladder.Entries == nil {
+		ladder.Entries = make(map[string]*Entry)
+	}
+	return ladder, nil
+}
+
+// Save writes the ladder to a JSON file
+func (l *Ladder) Save(path string) error {
+	data, err := json.MarshalIndent(l, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to encode ratings: %w", err)
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return fmt.Errorf("failed to create ratings directory: %w", err)
+	}
+
+	// Write to a temporary file first so an interrupted save keeps the old ratings
+	tmp, err := os.CreateTemp(filepath.Dir(path), filepath.Base(path)+".tmp*")
+	if err != nil {
+		return fmt.Errorf("failed to create ratings file: %w", err)
+	}
+	defer os.Remove(tmp.Name())
+
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		return fmt.Errorf("failed to write ratings: %w", err)
+	}
+	if err := tmp.Close(); err != nil {
+		return fmt.Errorf("failed to write ratings: %w", err)
+	}
+	if err := os.Rename(tmp.Name(), path); err != nil {
+		return fmt.Errorf("failed to move ratings into place: %w", err)
+	}
+	return nil
+}
+
+// SetGame records the game the ladder rates
+// It returns an error if the ladder already holds ratings for a different game,
+// since ratings from different games can't be compared.
+func (l *Ladder) SetGame(name string) error {
+	if l.Game != "" && l.Game != name {
+		return fmt.Errorf("ratings are for %s, not %s", l.Game, name)
+	}
+	l.Game = name
+	return nil
+}
+
+// Entry returns the entry for a player, creating it with DefaultRating if needed
+func (l *Ladder) Entry(name string) *Entry {
+	entry, ok := l.Entries[name]
+	if !ok {
+		entry = &Entry{Name: name, Rating: DefaultRating}
+		l.Entries[name] = entry
+	}
+	return entry
+}
+
+// ExpectedScore returns the expected score of a player rated ratingA against one rated ratingB
+func ExpectedScore(ratingA, ratingB float64) float64 {
+	return 1.0 / (1.0 + math.Pow(10, (ratingB-ratingA)/400.0))
+}
+
+// RecordGame updates both players' ratings after a game
+// scoreA is player A's result: 1 for a win, 0.5 for a draw and 0 for a loss
+func (l *Ladder) RecordGame(playerA, playerB string, scoreA float64) {
+	a := l.Entry(playerA)
+	b := l.Entry(playerB)
+
+	expectedA := ExpectedScore(a.Rating, b.Rating)
+	delta := l.K * (scoreA - expectedA)
+	a.Rating += delta
+	b.Rating -= delta
+
+	a.Games++
+	b.Games++
+	a.met(playerB)
+	b.met(playerA)
+	switch {
+	case scoreA > 0.5:
+		a.Wins++
+		b.Losses++
+	case scoreA < 0.5:
+		a.Losses++
+		b.Wins++
+	default:
+		a.Draws++
+		b.Draws++
+	}
+}
+
+// met counts a game against the opponent
+func (e *Entry) met(opponent string) {
+	if e.Opponents == nil {
+		e.Opponents = make(map[string]int)
+	}
+	e.Opponents[opponent]++
+}
+
+// Leaderboard returns all entries sorted by rating, highest first
+func (l *Ladder) Leaderboard() []Entry {
+	entries := make([]Entry, 0, len(l.Entries))
+	for _, entry := range l.Entries {
+		entries = append(entries, *entry)
+	}
+	sort.Slice(entries, func(i, j int) bool {
+		if entries[i].Rating != entries[j].Rating {
+			return entries[i].Rating > entries[j].Rating
+		}
+		return entries[i].Name < entries[j].Name
+	})
+	return entries
+}
+
+// Label returns the name to show for the entry: its path, if it has one, followed by its name
+func (e Entry) Label() string {
+	if e.Path == "" {
+		return e.Name
+	}
+	return e.Path + " " + e.Name
+}
+
+// Print writes the leaderboard as a table
+// The player column is widened to fit the longest label
+func (l *Ladder) Print(w io.Writer) {
+	entries := l.Leaderboard()
+	width := 28
+	for _, entry := range entries {
+		width = max(width, len(entry.Label()))
+	}
+
+	fmt.Fprintf(w, "%-4s %-*s %7s %6s %6s %6s %6s\n", "Rank", width, "Player", "Rating", "Games", "Wins", "Draws", "Losses")
+	for i, entry := range entries {
+		fmt.Fprintf(w, "%-4d %-*s %7.1f %6d %6d %6d %6d\n",
+			i+1, width, entry.Label(), entry.Rating, entry.Games, entry.Wins, entry.Draws, entry.Losses)
+	}
+}
+
+// Pairing is a match between two players, identified by their index in a player list
+type Pairing struct {
+	A int
+	B int
+}
+
+// RoundRobin returns pairings in which each of n players meets every other player once
+func RoundRobin(n int) []Pairing {
+	pairings := make([]Pairing, 0, n*(n-1)/2)
+	for a := 0; a < n; a++ {
+		for b := a + 1; b < n; b++ {
+			pairings = append(pairings, Pairing{A: a, B: b})
+		}
+	}
+	return pairings
+}
+
+// SwissRound returns pairings for one Swiss-system round
+// Going down the players sorted by their current rating, each unpaired player
+// meets the nearest-rated unpaired player they have not played yet, so each round
+// matches players of similar strength without repeating pairs. Once a player has
+// met everyone left, they meet the one they have played least. With an odd number
+// of players the one left over sits the round out.
+func (l *Ladder) SwissRound(names []string) []Pairing {
+	order := make([]int, len(names))
+	for i := range order {
+		order[i] = i
+	}
+	sort.SliceStable(order, func(i, j int) bool {
+		return l.Entry(names[order[i]]).Rating > l.Entry(names[order[j]]).Rating
+	})
+
+	paired := make([]bool, len(order))
+	pairings := make([]Pairing, 0, len(names)/2)
+	for i := range order {
+		if paired[i] {
+			continue
+		}
+		entry := l.Entry(names[order[i]])
+
+		// Ratings fall down the order, so the first candidate with the fewest
+		// previous games is the nearest-rated one
+		best := -1
+		for j := i + 1; j < len(order); j++ {
+			if paired[j] {
+				continue
+			}
+			if best == -1 || entry.Opponents[names[order[j]]] < entry.Opponents[names[order[best]]] {
+				best = j
+			}
+		}
+		if best == -1 {
+			break
+		}
+
+		paired[i], paired[best] = true, true
+		pairings = append(pairings, Pairing{A: order[i], B: order[best]})
+	}
+	return pairings
+}
@@ -0,0 +1,115 @@
+package rating
+
+import (
+	"math"
+	"path/filepath"
+	"testing"
+)
+
+func TestRecordGame(t *testing.T) {
+	ladder := NewLadder(DefaultK)
+
+	if e := ExpectedScore(1500, 1500); e != 0.5 {
+		t.Errorf("ExpectedScore(1500, 1500) = %v, want 0.5", e)
+	}
+	if e := ExpectedScore(1900, 1500); math.Abs(e-0.9091) > 1e-4 {
+		t.Errorf("ExpectedScore(1900, 1500) = %v, want about 0.909", e)
+	}
+
+	ladder.RecordGame("a", "b", 1)
+	a, b := ladder.Entry("a"), ladder.Entry("b")
+	if a.Rating != 1516 || b.Rating != 1484 {
+		t.Errorf("ratings after win = %v/%v, want 1516/1484", a.Rating, b.Rating)
+	}
+
+	// A draw between unequal players moves ratings towards each other
+	ladder.RecordGame("a", "b", 0.5)
+	if a.Rating >= 1516 || b.Rating <= 1484 {
+		t.Errorf("draw should narrow the gap, got %v/%v", a.Rating, b.Rating)
+	}
+	if math.Abs(a.Rating+b.Rating-2*DefaultRating) > 1e-9 {
+		t.Errorf("rating changes should be zero-sum, got %v/%v", a.Rating, b.Rating)
+	}
+	if a.Wins != 1 || a.Draws != 1 || b.Losses != 1 || b.Games != 2 {
+		t.Errorf("unexpected game counts: a=%+v b=%+v", a, b)
+	}
+
+	board := ladder.Leaderboard()
+	if len(board) != 2 || board[0].Name != "a" {
+		t.Errorf("Leaderboard() = %+v, want a first", board)
+	}
+}
+
+func TestSaveLoadLadder(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "ratings.json")
+
+	ladder, err := LoadLadder(path)
+	if err != nil {
+		t.Fatalf("LoadLadder on missing file returned error: %v", err)
+	}
+	ladder.RecordGame("a", "b", 0)
+	ladder.Entry("b").Path = "/models/best.json"
+	if err := ladder.SetGame("tictactoe"); err != nil {
+		t.Fatalf("SetGame returned error: %v", err)
+	}
+	if err := ladder.Save(path); err != nil {
+		t.Fatalf("Save returned error: %v", err)
+	}
+
+	loaded, err := LoadLadder(path)
+	if err != nil {
+		t.Fatalf("LoadLadder returned error: %v", err)
+	}
+	if loaded.Entry("b").Rating != ladder.Entry("b").Rating || loaded.K != DefaultK {
+		t.Errorf("loaded ladder %+v does not match saved ladder", loaded.Entries["b"])
+	}
+	if label := loaded.Entry("b").Label(); label != "/models/best.json b" {
+		t.Errorf("Label() = %q, want the path followed by the name", label)
+	}
+	if files, _ := filepath.Glob(path + ".tmp*"); len(files) > 0 {
+		t.Errorf("Save left temporary files behind: %v", files)
+	}
+
+	// The ratings stay tied to their game
+	if err := loaded.SetGame("connectfour"); err == nil {
+		t.Error("SetGame should reject ratings from another game")
+	}
+	if err := loaded.SetGame("tictactoe"); err != nil {
+		t.Errorf("SetGame(%q) returned error: %v", loaded.Game, err)
+	}
+}
+
+func TestPairings(t *testing.T) {
+	if n := len(RoundRobin(5)); n != 10 {
+		t.Errorf("RoundRobin(5) has %d pairings, want 10", n)
+	}
+
+	ladder := NewLadder(DefaultK)
+	ladder.Entry("weak").Rating = 1200
+	ladder.Entry("strong").Rating = 1800
+	ladder.Entry("good").Rating = 1700
+	ladder.Entry("bye").Rating = 1000
+
+	names := []string{"weak", "strong", "good", "bye", "average"}
+	pairings := ladder.SwissRound(names)
+	if len(pairings) != 2 {
+		t.Fatalf("SwissRound returned %d pairings, want 2", len(pairings))
+	}
+	if names[pairings[0].A] != "strong" || names[pairings[0].B] != "good" {
+		t.Errorf("first pairing = %s vs %s, want strong vs good", names[pairings[0].A], names[pairings[0].B])
+	}
+	if names[pairings[1].A] != "average" || names[pairings[1].B] != "weak" {
+		t.Errorf("second pairing = %s vs %s, want average vs weak", names[pairings[1].A], names[pairings[1].B])
+	}
+
+	// The next round avoids rematches: strong and good have met, so strong meets average
+	ladder.RecordGame("strong", "good", 1)
+	ladder.RecordGame("average", "weak", 0.5)
+	previous := map[[2]string]bool{{"strong", "good"}: true, {"average", "weak"}: true}
+	for _, pairing := range ladder.SwissRound(names) {
+		a, b := names[pairing.A], names[pairing.B]
+		if previous[[2]string{a, b}] || previous[[2]string{b, a}] {
+			t.Errorf("round two repeats %s vs %s", a, b)
+		}
+	}
+}
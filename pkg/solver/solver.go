@@ -0,0 +1,195 @@
+package solver
+
+import (
+	"math/rand/v2"
+
+	"github.com/ZachBeta/go_neural_network_learning/pkg/game"
+)
+
+// Game-theoretic values of a position, from the point of view of the player to move
+const (
+	Loss = -1
+	Draw = 0
+	Win  = 1
+)
+
+// bound records how a transposition table value relates to the true value
+type bound int
+
+const (
+	exact bound = iota
+	lowerBound
+	upperBound
+)
+
+// entry is a transposition table entry
+type entry struct {
+	value int
+	bound bound
+}
+
+// Solver computes perfect play for tic-tac-toe using negamax search with
+// alpha-beta pruning and a transposition table
+// The table is kept between queries, so reusing a Solver makes later queries cheap
+type Solver struct {
+	table map[uint32]entry
+}
+
+// New creates a new solver with an empty transposition table
+func New() *Solver {
+	return &Solver{
+		table: make(map[uint32]entry),
+	}
+}
+
+// Value returns the game-theoretic value of the board for the player to move:
+// Win, Draw or Loss, assuming perfect play from both sides
+func (s *Solver) Value(board *game.Board) int {
+	return s.negamax(searchBoard(board), Loss, Win)
+}
+
+// MoveValues returns the game-theoretic value of every legal move, keyed by move index (0-8)
+// Values are from the point of view of the player making the move
+// The result is empty if the game is already over
+func (s *Solver) MoveValues(board *game.Board) map[int]int {
+	b := searchBoard(board)
+	values := make(map[int]int)
+	for _, move := range b.LegalMoves() {
+		b.Apply(move)
+		values[move] = -s.negamax(b, Loss, Win)
+		b.UndoMove()
+	}
+
+	return values
+}
+
+// OptimalMoves returns every move that achieves the board's game-theoretic value,
+// in increasing order of move index
+func (s *Solver) OptimalMoves(board *game.Board) []int {
+	values := s.MoveValues(board)
+
+	best := Loss
+	for _, value := range values {
+		if value > best {
+			best = value
+		}
+	}
+
+	moves := make([]int, 0, len(values))
+	for i := 0; i < 9; i++ {
+		if value, ok := values[i]; ok && value == best {
+			moves = append(moves, i)
+		}
+	}
+
+	return moves
+}
+
+// BestMove returns the lowest-indexed optimal move, or -1 if the game is over
+func (s *Solver) BestMove(board *game.Board) int {
+	moves := s.OptimalMoves(board)
+	if len(moves) == 0 {
+		return -1
+	}
+	return moves[0]
+}
+
+// negamax returns the value of the board for the player to move
+// Moves are made and undone on the board, which is left as it was found
+func (s *Solver) negamax(board *game.Board, alpha, beta int) int {
+	alphaOrig := alpha
+	key := tableKey(board)
+
+	if e, ok := s.table[key]; ok {
+		switch e.bound {
+		case exact:
+			return e.value
+		case lowerBound:
+			alpha = max(alpha, e.value)
+		case upperBound:
+			beta = min(beta, e.value)
+		}
+		if alpha >= beta {
+			return e.value
+		}
+	}
+
+	switch board.GetStatus() {
+	case game.Won:
+		// The previous player completed a line, so the player to move has lost
+		return Loss
+	case game.Draw:
+		return Draw
+	}
+
+	best := Loss
+	for _, move := range board.LegalMoves() {
+		board.Apply(move)
+		value := -s.negamax(board, -beta, -alpha)
+		board.UndoMove()
+
+		best = max(best, value)
+		alpha = max(alpha, value)
+		if alpha >= beta {
+			break
+		}
+	}
+
+	e := entry{value: best, bound: exact}
+	if best <= alphaOrig {
+		e.bound = upperBound
+	} else if best >= beta {
+		e.bound = lowerBound
+	}
+	s.table[key] = e
+
+	return best
+}
+
+// searchBoard returns a copy of the board for the search to play on
+// Its status is brought up to date in case the board was set up with Set.
+func searchBoard(board *game.Board) *game.Board {
+	b := board.Clone()
+	b.CheckWinner()
+	return b
+}
+
+// tableKey identifies a position exactly: the board's base-3 Key and the player to move
+func tableKey(board *game.Board) uint32 {
+	key := uint32(board.Key()) << 1
+	if board.GetCurrentPlayer() == game.O {
+		key |= 1
+	}
+	return key
+}
+
+// PerfectPlayer always plays an optimal move
+// When several moves are optimal it picks one at random, so games against it vary
+type PerfectPlayer struct {
+	solver *Solver
+	rng    *rand.Rand
+}
+
+// NewPerfectPlayer creates a perfect player
+// If rng is nil the player always picks the lowest-indexed optimal move
+func NewPerfectPlayer(solver *Solver, rng *rand.Rand) *PerfectPlayer {
+	if solver == nil {
+		solver = New()
+	}
+	return &PerfectPlayer{
+		solver: solver,
+		rng:    rng,
+	}
+}
+
+// SelectMove returns an optimal move for the board, or -1 if the game is over
+func (p *PerfectPlayer) SelectMove(board *game.Board) int {
+	moves := p.solver.OptimalMoves(board)
+	if len(moves) == 0 {
+		return -1
+	}
+	if p.rng == nil {
+		return moves[0]
+	}
+	return moves[p.rng.IntN(len(moves))]
+}
@@ -0,0 +1,92 @@
+package solver
+
+import (
+	"math/rand/v2"
+	"testing"
+
+	"github.com/ZachBeta/go_neural_network_learning/internal/gametest"
+	"github.com/ZachBeta/go_neural_network_learning/internal/utils"
+	"github.com/ZachBeta/go_neural_network_learning/pkg/game"
+)
+
+func TestValue(t *testing.T) {
+	utils.SetLogLevel(utils.ERROR)
+
+	tests := []struct {
+		name     string
+		moves    []int
+		expected int
+	}{
+		{"empty board is a draw", nil, Draw},
+		{"X to move can win", []int{0, 3, 1, 4}, Win},
+		{"O faces a fork", []int{0, 4, 8, 2, 6}, Loss},
+		{"game already won by previous player", []int{0, 3, 1, 4, 2}, Loss},
+		{"corner opening answered by edge loses for O", []int{0, 1}, Win},
+	}
+
+	s := New()
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			board := gametest.Board(t, tt.moves...)
+			if got := s.Value(board); got != tt.expected {
+				t.Errorf("Value() = %d, want %d\n%s", got, tt.expected, board)
+			}
+		})
+	}
+}
+
+func TestOptimalMoves(t *testing.T) {
+	utils.SetLogLevel(utils.ERROR)
+	s := New()
+
+	// X threatens the top row; O must block at index 2
+	board := gametest.Board(t, 0, 4, 1)
+	moves := s.OptimalMoves(board)
+	if len(moves) != 1 || moves[0] != 2 {
+		t.Errorf("OptimalMoves() = %v, want [2]", moves)
+	}
+
+	// X can complete the top row immediately
+	board = gametest.Board(t, 0, 3, 1, 4)
+	values := s.MoveValues(board)
+	if values[2] != Win {
+		t.Errorf("MoveValues()[2] = %d, want %d", values[2], Win)
+	}
+	if values[s.BestMove(board)] != Win {
+		t.Errorf("BestMove() = %d is not winning", s.BestMove(board))
+	}
+
+	// No moves once the game is over
+	board = gametest.Board(t, 0, 3, 1, 4, 2)
+	if moves := s.OptimalMoves(board); len(moves) != 0 {
+		t.Errorf("OptimalMoves() on finished game = %v, want none", moves)
+	}
+	if s.BestMove(board) != -1 {
+		t.Errorf("BestMove() on finished game = %d, want -1", s.BestMove(board))
+	}
+}
+
+func TestPerfectPlayersDraw(t *testing.T) {
+	utils.SetLogLevel(utils.ERROR)
+	s := New()
+	rng := rand.New(rand.NewPCG(1, 2))
+	x := NewPerfectPlayer(s, rng)
+	o := NewPerfectPlayer(s, rng)
+
+	for i := 0; i < 20; i++ {
+		board := game.NewBoard()
+		for board.GetStatus() == game.InProgress {
+			player := x
+			if board.GetCurrentPlayer() == game.O {
+				player = o
+			}
+			move := player.SelectMove(board)
+			if _, err := board.MakeMove(move/3, move%3); err != nil {
+				t.Fatalf("move %d rejected: %v\n%s", move, err, board)
+			}
+		}
+		if board.GetStatus() != game.Draw {
+			t.Fatalf("perfect play should draw, got:\n%s", board)
+		}
+	}
+}
@@ -0,0 +1,63 @@
+package strategy
+
+import (
+	"github.com/ZachBeta/go_neural_network_learning/pkg/game"
+)
+
+// The detectors in this package take the board as the player faced it,
+// before the move was made, and a move index (0-8) for the player to move.
+
+// IsWinningMove checks if a move wins the game immediately
+func IsWinningMove(board *game.Board, move int) bool {
+	// Make a temporary board to test the move
+	tempBoard := board.Clone()
+	result, err := tempBoard.Apply(move)
+	if err != nil {
+		return false
+	}
+
+	// Check if this move wins
+	return result.Status == game.Won
+}
+
+// IsBlockingMove checks if a move blocks the opponent's winning move
+// That is the case when the opponent would win by playing the same cell
+func IsBlockingMove(board *game.Board, move int) bool {
+	// Give the turn to the opponent and see if the cell wins for them
+	opponentBoard := board.Clone()
+	opponentBoard.SwitchPlayer()
+	return IsWinningMove(opponentBoard, move)
+}
+
+// IsForkCreation checks if a move creates a fork
+// A fork leaves the player with at least two different winning moves
+func IsForkCreation(board *game.Board, move int) bool {
+	// Make a temporary board to test the move
+	tempBoard := board.Clone()
+	result, err := tempBoard.Apply(move)
+	if err != nil || result.Status != game.InProgress {
+		return false
+	}
+
+	// Give the turn back to the player and count their winning moves
+	tempBoard.SwitchPlayer()
+	return CountWinningMoves(tempBoard) >= 2
+}
+
+// IsForkBlocking checks if a move takes the cell the opponent needed to create a fork
+func IsForkBlocking(board *game.Board, move int) bool {
+	opponentBoard := board.Clone()
+	opponentBoard.SwitchPlayer()
+	return IsForkCreation(opponentBoard, move)
+}
+
+// CountWinningMoves counts the moves that would win immediately for the player to move
+func CountWinningMoves(board *game.Board) int {
+	count := 0
+	for _, move := range board.LegalMoves() {
+		if IsWinningMove(board, move) {
+			count++
+		}
+	}
+	return count
+}
@@ -0,0 +1,39 @@
+package strategy
+
+import (
+	"testing"
+
+	"github.com/ZachBeta/go_neural_network_learning/internal/gametest"
+	"github.com/ZachBeta/go_neural_network_learning/internal/utils"
+	"github.com/ZachBeta/go_neural_network_learning/pkg/game"
+)
+
+func TestDetectors(t *testing.T) {
+	utils.SetLogLevel(utils.ERROR)
+
+	tests := []struct {
+		name     string
+		moves    []int
+		move     int
+		detector func(*game.Board, int) bool
+		expected bool
+	}{
+		{"X completes top row", []int{0, 3, 1, 4}, 2, IsWinningMove, true},
+		{"X plays elsewhere", []int{0, 3, 1, 4}, 8, IsWinningMove, false},
+		{"O blocks top row", []int{0, 4, 1}, 2, IsBlockingMove, true},
+		{"O ignores threat", []int{0, 4, 1}, 8, IsBlockingMove, false},
+		{"X creates fork", []int{0, 4, 8, 2}, 6, IsForkCreation, true},
+		{"X makes single threat", []int{0, 4, 8, 2}, 1, IsForkCreation, false},
+		{"O takes X's fork square", []int{0, 4, 8}, 6, IsForkBlocking, true},
+		{"O leaves X's fork squares", []int{0, 4, 8}, 1, IsForkBlocking, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			board := gametest.Board(t, tt.moves...)
+			if got := tt.detector(board, tt.move); got != tt.expected {
+				t.Errorf("detector(move %d) = %v, want %v\n%s", tt.move, got, tt.expected, board)
+			}
+		})
+	}
+}
@@ -0,0 +1,385 @@
+package ultimate
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/ZachBeta/go_neural_network_learning/pkg/game"
+)
+
+// Cells is the number of cells on the 9x9 board, which is also the number of moves
+const Cells = 81
+
+// InputSize is the length of the network input produced by Encode:
+// planes for the stones of the player to move, the opponent's stones and the legal moves
+const InputSize = 3 * Cells
+
+// AnyBoard is returned by NextBoard when the player to move may play in any open sub-board
+const AnyBoard = -1
+
+// Board is a game.State
+var _ game.State = (*Board)(nil)
+
+// Game is the game.Game for Ultimate Tic-Tac-Toe
+type Game struct{}
+
+// Name returns the name of the game
+func (Game) Name() string {
+	return "ultimate"
+}
+
+// NewState returns an empty board
+func (Game) NewState() game.State {
+	return NewBoard()
+}
+
+// InputSize returns the length of the vector returned by Encode
+func (Game) InputSize() int {
+	return InputSize
+}
+
+// ActionSize returns the number of cells
+func (Game) ActionSize() int {
+	return Cells
+}
+
+// InvalidMoveError is returned for a move outside the board
+type InvalidMoveError struct {
+	Move int
+}
+
+func (e *InvalidMoveError) Error() string {
+	return fmt.Sprintf("invalid move %d", e.Move)
+}
+
+// WrongBoardError is returned when a move is made outside the sub-board the player was sent to
+type WrongBoardError struct {
+	Board    int
+	Required int
+}
+
+func (e *WrongBoardError) Error() string {
+	return fmt.Sprintf("move in board %d, but the last move sends play to board %d", e.Board, e.Required)
+}
+
+// BoardClosedError is returned when a move is made in a sub-board that is already won or full
+type BoardClosedError struct {
+	Board int
+}
+
+func (e *BoardClosedError) Error() string {
+	return fmt.Sprintf("board %d is already decided", e.Board)
+}
+
+// Move returns the move index for a cell (0-8) of a sub-board (0-8)
+// Sub-boards and their cells are both numbered row by row, like tic-tac-toe moves.
+func Move(board, cell int) int {
+	return board*9 + cell
+}
+
+// SplitMove returns the sub-board and the cell within it for a move index
+func SplitMove(move int) (board, cell int) {
+	return move / 9, move % 9
+}
+
+// RowCol returns the row and column (0-8) of a move on the full 9x9 grid
+func RowCol(move int) (row, col int) {
+	board, cell := SplitMove(move)
+	return board/3*3 + cell/3, board%3*3 + cell%3
+}
+
+// FromRowCol returns the move index for a row and column (0-8) of the full 9x9 grid
+func FromRowCol(row, col int) int {
+	return Move(row/3*3+col/3, row%3*3+col%3)
+}
+
+// Board is an Ultimate Tic-Tac-Toe board: nine tic-tac-toe sub-boards arranged
+// in a 3x3 meta-board. The cell a player picks within a sub-board sends the
+// opponent to the sub-board in the same position; if that one is already decided
+// the opponent may play anywhere. Winning a sub-board claims its meta-board cell,
+// and three claimed cells in a row win the game.
+type Board struct {
+	boards [9]*game.Board
+	// meta holds the winner of each sub-board; drawn sub-boards stay empty
+	meta          *game.Board
+	currentPlayer game.Cell
+	status        game.GameStatus
+	next          int
+	// moves holds every move, oldest first
+	moves []int
+}
+
+// NewBoard creates an empty board with X to move anywhere
+func NewBoard() *Board {
+	b := &Board{
+		meta:          game.NewBoard(),
+		currentPlayer: game.X,
+		status:        game.InProgress,
+		next:          AnyBoard,
+	}
+	for i := range b.boards {
+		b.boards[i] = game.NewBoard()
+	}
+	return b
+}
+
+// Get returns the cell value at the given row and column (0-8) of the full grid
+func (b *Board) Get(row, col int) game.Cell {
+	if row < 0 || row >= 9 || col < 0 || col >= 9 {
+		return game.Empty
+	}
+	board, cell := SplitMove(FromRowCol(row, col))
+	return b.boards[board].Get(cell/3, cell%3)
+}
+
+// GetCurrentPlayer returns the player to move
+func (b *Board) GetCurrentPlayer() game.Cell {
+	return b.currentPlayer
+}
+
+// GetStatus returns the current game status
+func (b *Board) GetStatus() game.GameStatus {
+	return b.status
+}
+
+// NextBoard returns the sub-board the player to move must play in, or AnyBoard
+func (b *Board) NextBoard() int {
+	return b.next
+}
+
+// SubBoard returns a copy of a sub-board with the player to move set to the
+// player to move in the whole game, so it can be handed to the solver or the
+// strategy detectors
+func (b *Board) SubBoard(i int) *game.Board {
+	sub := b.boards[i].Clone()
+	if sub.GetCurrentPlayer() != b.currentPlayer {
+		sub.SwitchPlayer()
+	}
+	return sub
+}
+
+// Meta returns a copy of the meta-board, where each cell holds the winner of its sub-board
+func (b *Board) Meta() *game.Board {
+	meta := b.meta.Clone()
+	meta.CheckWinner()
+	return meta
+}
+
+// Winner returns the player who won three sub-boards in a row and the sub-boards
+// of that line. It returns game.Empty and nil if nobody has won.
+func (b *Board) Winner() (game.Cell, []int) {
+	return b.meta.Winner()
+}
+
+// MakeMove plays a cell of a sub-board for the current player
+// A rejected move leaves the board unchanged and returns a *game.GameOverError,
+// *InvalidMoveError, *WrongBoardError, *BoardClosedError or *game.CellOccupiedError.
+func (b *Board) MakeMove(board, cell int) (game.MoveResult, error) {
+	if b.status != game.InProgress {
+		return game.MoveResult{}, &game.GameOverError{Status: b.status}
+	}
+	if board < 0 || board >= 9 || cell < 0 || cell >= 9 {
+		return game.MoveResult{}, &InvalidMoveError{Move: Move(board, cell)}
+	}
+	if b.next != AnyBoard && board != b.next {
+		return game.MoveResult{}, &WrongBoardError{Board: board, Required: b.next}
+	}
+	sub := b.boards[board]
+	if sub.GetStatus() != game.InProgress {
+		return game.MoveResult{}, &BoardClosedError{Board: board}
+	}
+
+	row, col := cell/3, cell%3
+	if occupant := sub.Get(row, col); occupant != game.Empty {
+		return game.MoveResult{}, fmt.Errorf("board %d: %w", board, &game.CellOccupiedError{Row: row, Col: col, Occupant: occupant})
+	}
+
+	// A player may move in the same sub-board several times in a row,
+	// so hand the sub-board to the mover first; the move can no longer be rejected
+	player := b.currentPlayer
+	if sub.GetCurrentPlayer() != player {
+		sub.SwitchPlayer()
+	}
+	subResult, err := sub.MakeMove(row, col)
+	if err != nil {
+		return game.MoveResult{}, fmt.Errorf("board %d: %w", board, err)
+	}
+	if subResult.Status == game.Won {
+		b.meta.Set(board/3, board%3, player)
+	}
+
+	b.moves = append(b.moves, Move(board, cell))
+	b.currentPlayer = opponent(player)
+	b.next = b.nextBoard(cell)
+	b.updateStatus()
+
+	result := game.MoveResult{
+		Player: player,
+		Index:  Move(board, cell),
+		Status: b.status,
+	}
+	if b.status == game.Won {
+		result.Winner, result.Line = b.Winner()
+	}
+	return result, nil
+}
+
+// Apply plays the move with the given index (see Move); it makes Board a game.State
+func (b *Board) Apply(move int) (game.MoveResult, error) {
+	if move < 0 || move >= Cells {
+		return game.MoveResult{}, &InvalidMoveError{Move: move}
+	}
+	return b.MakeMove(SplitMove(move))
+}
+
+// Undo takes back the last move
+// It returns false if there is no move to undo.
+func (b *Board) Undo() bool {
+	if len(b.moves) == 0 {
+		return false
+	}
+	board, _ := SplitMove(b.moves[len(b.moves)-1])
+	b.moves = b.moves[:len(b.moves)-1]
+
+	b.boards[board].UndoMove()
+	b.meta.Set(board/3, board%3, game.Empty)
+	b.currentPlayer = opponent(b.currentPlayer)
+	b.status = game.InProgress
+
+	b.next = AnyBoard
+	if len(b.moves) > 0 {
+		_, cell := SplitMove(b.moves[len(b.moves)-1])
+		b.next = b.nextBoard(cell)
+	}
+	return true
+}
+
+// LastMove returns the index of the last move, or -1 if no move has been made
+func (b *Board) LastMove() int {
+	if len(b.moves) == 0 {
+		return -1
+	}
+	return b.moves[len(b.moves)-1]
+}
+
+// LegalMoves returns the empty cells of the sub-boards the player may play in,
+// or none once the game is over
+func (b *Board) LegalMoves() []int {
+	moves := make([]int, 0, Cells)
+	if b.status != game.InProgress {
+		return moves
+	}
+	for board, sub := range b.boards {
+		if b.next != AnyBoard && board != b.next {
+			continue
+		}
+		for _, cell := range sub.LegalMoves() {
+			moves = append(moves, Move(board, cell))
+		}
+	}
+	return moves
+}
+
+// ActionSize returns the number of cells
+func (b *Board) ActionSize() int {
+	return Cells
+}
+
+// CloneState returns a copy of the board as a game.State
+func (b *Board) CloneState() game.State {
+	return b.Clone()
+}
+
+// Clone creates a deep copy of the board
+func (b *Board) Clone() *Board {
+	clone := &Board{
+		meta:          b.meta.Clone(),
+		currentPlayer: b.currentPlayer,
+		status:        b.status,
+		next:          b.next,
+		moves:         append([]int(nil), b.moves...),
+	}
+	for i, sub := range b.boards {
+		clone.boards[i] = sub.Clone()
+	}
+	return clone
+}
+
+// String returns the 9x9 grid with the sub-boards separated by lines
+func (b *Board) String() string {
+	var result strings.Builder
+	for row := 0; row < 9; row++ {
+		if row > 0 && row%3 == 0 {
+			result.WriteString("---+---+---\n")
+		}
+		for col := 0; col < 9; col++ {
+			if col > 0 && col%3 == 0 {
+				result.WriteByte('|')
+			}
+			switch b.Get(row, col) {
+			case game.X:
+				result.WriteByte('X')
+			case game.O:
+				result.WriteByte('O')
+			default:
+				result.WriteByte('.')
+			}
+		}
+		result.WriteByte('\n')
+	}
+	return result.String()
+}
+
+// Encode converts the board to a neural network input vector, indexed by move
+// The first plane marks the stones of the player to move, the second the
+// opponent's and the third the cells the player may play in, since the
+// network cannot work out which sub-board it was sent to from the stones alone
+func (b *Board) Encode() []float64 {
+	input := make([]float64, InputSize)
+	for board, sub := range b.boards {
+		for cell := 0; cell < 9; cell++ {
+			switch sub.Get(cell/3, cell%3) {
+			case b.currentPlayer:
+				input[Move(board, cell)] = 1.0
+			case game.Empty:
+			default:
+				input[Cells+Move(board, cell)] = 1.0
+			}
+		}
+	}
+	for _, move := range b.LegalMoves() {
+		input[2*Cells+move] = 1.0
+	}
+	return input
+}
+
+// nextBoard returns the sub-board a move in the given cell sends the opponent to
+func (b *Board) nextBoard(cell int) int {
+	if b.boards[cell].GetStatus() != game.InProgress {
+		return AnyBoard
+	}
+	return cell
+}
+
+// updateStatus ends the game once a player has three sub-boards in a row
+// or every sub-board is decided
+func (b *Board) updateStatus() {
+	if winner, _ := b.meta.Winner(); winner != game.Empty {
+		b.status = game.Won
+		return
+	}
+	for _, sub := range b.boards {
+		if sub.GetStatus() == game.InProgress {
+			return
+		}
+	}
+	b.status = game.Draw
+}
+
+// opponent returns the other player
+func opponent(player game.Cell) game.Cell {
+	if player == game.X {
+		return game.O
+	}
+	return game.X
+}
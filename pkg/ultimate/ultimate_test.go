@@ -0,0 +1,174 @@
+package ultimate
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/ZachBeta/go_neural_network_learning/internal/utils"
+	"github.com/ZachBeta/go_neural_network_learning/pkg/game"
+)
+
+// boardFromMoves plays the given move indices on a new board
+func boardFromMoves(t *testing.T, moves ...int) *Board {
+	t.Helper()
+	board := NewBoard()
+	for _, move := range moves {
+		if _, err := board.Apply(move); err != nil {
+			t.Fatalf("move %d rejected: %v\n%s", move, err, board)
+		}
+	}
+	return board
+}
+
+func TestMoveNumbering(t *testing.T) {
+	for move := 0; move < Cells; move++ {
+		row, col := RowCol(move)
+		if FromRowCol(row, col) != move {
+			t.Errorf("FromRowCol(RowCol(%d)) = %d", move, FromRowCol(row, col))
+		}
+	}
+	if row, col := RowCol(Move(5, 7)); row != 5 || col != 7 {
+		t.Errorf("RowCol(Move(5, 7)) = (%d,%d), want (5,7)", row, col)
+	}
+}
+
+func TestMakeMove(t *testing.T) {
+	utils.SetLogLevel(utils.ERROR)
+
+	// X plays the centre cell of the top-left board, sending O to the centre board
+	board := boardFromMoves(t, Move(0, 4))
+	if board.NextBoard() != 4 || board.GetCurrentPlayer() != game.O || board.Get(1, 1) != game.X {
+		t.Fatalf("next board %d, player %v\n%s", board.NextBoard(), board.GetCurrentPlayer(), board)
+	}
+	if moves := board.LegalMoves(); len(moves) != 9 || moves[0] != Move(4, 0) {
+		t.Errorf("LegalMoves() = %v, want the cells of board 4", moves)
+	}
+
+	tests := []struct {
+		name        string
+		board, cell int
+		check       func(error) bool
+	}{
+		{"wrong board", 0, 0, func(err error) bool { var e *WrongBoardError; return errors.As(err, &e) && e.Required == 4 }},
+		{"outside", 4, 9, func(err error) bool { var e *InvalidMoveError; return errors.As(err, &e) }},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := board.MakeMove(tt.board, tt.cell); !tt.check(err) {
+				t.Errorf("unexpected error %v", err)
+			}
+		})
+	}
+
+	// O plays the centre of board 4 and sends X back there, where the cell is taken
+	board.MakeMove(4, 4)
+	var occupied *game.CellOccupiedError
+	if _, err := board.MakeMove(4, 4); !errors.As(err, &occupied) {
+		t.Errorf("expected CellOccupiedError, got %v", err)
+	}
+	if board.LastMove() != Move(4, 4) {
+		t.Errorf("rejected move changed the board")
+	}
+}
+
+func TestRejectedMoveKeepsSubBoardPlayer(t *testing.T) {
+	utils.SetLogLevel(utils.ERROR)
+
+	// O moved last in board 4, so its next mover there is X; O is sent back and
+	// tries the taken centre
+	board := boardFromMoves(t, Move(4, 4), Move(4, 0), Move(0, 4))
+	before := board.Clone()
+
+	var occupied *game.CellOccupiedError
+	if _, err := board.MakeMove(4, 4); !errors.As(err, &occupied) {
+		t.Fatalf("expected CellOccupiedError, got %v", err)
+	}
+	if player := board.boards[4].GetCurrentPlayer(); player != before.boards[4].GetCurrentPlayer() {
+		t.Errorf("rejected move changed board 4's player to %v", player)
+	}
+	if board.GetCurrentPlayer() != game.O || board.NextBoard() != 4 || board.String() != before.String() {
+		t.Errorf("rejected move changed the board:\n%s", board)
+	}
+}
+
+func TestSubBoardWinAndUndo(t *testing.T) {
+	utils.SetLogLevel(utils.ERROR)
+
+	// X takes the top row of board 0 while O answers in boards 1, 2 and 0
+	board := boardFromMoves(t,
+		Move(0, 1), Move(1, 0), // O sent back to board 0
+		Move(0, 2), Move(2, 0),
+		Move(0, 0),
+	)
+	if board.Meta().Get(0, 0) != game.X || board.SubBoard(0).GetStatus() != game.Won {
+		t.Fatalf("board 0 not claimed by X\n%s", board)
+	}
+
+	// O is sent to the decided board 0, so it may play anywhere else;
+	// its move sends X back to board 0, which frees X as well
+	if board.NextBoard() != AnyBoard {
+		t.Errorf("next board %d, want any board", board.NextBoard())
+	}
+	board.Apply(Move(3, 0))
+	if board.NextBoard() != AnyBoard || len(board.LegalMoves()) != Cells-9-3 {
+		t.Errorf("next board %d with %d legal moves, want any board", board.NextBoard(), len(board.LegalMoves()))
+	}
+	var closed *BoardClosedError
+	if _, err := board.MakeMove(0, 5); !errors.As(err, &closed) {
+		t.Errorf("expected BoardClosedError, got %v", err)
+	}
+
+	// Undoing back past the winning move reopens board 0
+	board.Undo()
+	board.Undo()
+	if board.Meta().Get(0, 0) != game.Empty || board.NextBoard() != 0 || board.GetCurrentPlayer() != game.X {
+		t.Errorf("undo did not restore the position\n%s", board)
+	}
+}
+
+func TestWinner(t *testing.T) {
+	utils.SetLogLevel(utils.ERROR)
+
+	// X wins boards 0, 1 and 2 using their top rows while O is sent back and forth
+	board := boardFromMoves(t,
+		Move(0, 3), Move(3, 0), Move(0, 4), Move(4, 0), Move(0, 5), // X wins board 0 with its middle row
+		Move(5, 1), Move(1, 3), Move(3, 1), Move(1, 4), Move(4, 1), Move(1, 5), // X wins board 1
+		Move(5, 2), Move(2, 3), Move(3, 2), Move(2, 4), Move(4, 2),
+	)
+	if board.GetStatus() != game.InProgress {
+		t.Fatalf("game over too early\n%s", board)
+	}
+	result, err := board.MakeMove(2, 5)
+	if err != nil {
+		t.Fatalf("winning move rejected: %v", err)
+	}
+	if result.Status != game.Won || result.Winner != game.X || len(result.Line) != 3 || result.Line[2] != 2 {
+		t.Errorf("MoveResult = %+v, want X to win on boards 0-2", result)
+	}
+	if len(board.LegalMoves()) != 0 {
+		t.Errorf("finished game has legal moves %v", board.LegalMoves())
+	}
+}
+
+func TestEncode(t *testing.T) {
+	utils.SetLogLevel(utils.ERROR)
+
+	board := boardFromMoves(t, Move(0, 4))
+	clone := board.Clone()
+	input := board.Encode()
+	if len(input) != InputSize {
+		t.Fatalf("Encode() has %d values, want %d", len(input), InputSize)
+	}
+	// O is to move: X's stone is the opponent's and only board 4 is playable
+	if input[Move(0, 4)] != 0 || input[Cells+Move(0, 4)] != 1 {
+		t.Errorf("stone planes do not follow the player to move")
+	}
+	if input[2*Cells+Move(4, 0)] != 1 || input[2*Cells+Move(3, 0)] != 0 {
+		t.Errorf("legal move plane does not match the next board")
+	}
+
+	clone.Apply(Move(4, 4))
+	if board.Get(4, 4) != game.Empty || board.LastMove() != Move(0, 4) {
+		t.Errorf("clone shares state with the original")
+	}
+}